@@ -0,0 +1,60 @@
+package httperr
+
+import (
+	"net/http"
+	"sync"
+)
+
+var categories = struct {
+	sync.RWMutex
+	m map[int]string
+}{m: make(map[int]string)}
+
+// RegisterCategory overrides the category Category returns for a status code
+func RegisterCategory(code int, category string) {
+	categories.Lock()
+	defer categories.Unlock()
+	categories.m[code] = category
+}
+
+// Category classifies err into a stable user-facing category derived from its status code.
+// The categories are "bad_request" (400), "unauthorized" (401), "forbidden" (403),
+// "not_found" (404), "conflict" (409), "validation" (422), "rate_limited" (429),
+// "client" for other 4xx, "server" for 5xx and "unknown" for other codes.
+// It returns "" for nil errors. Categories can be changed with RegisterCategory.
+func Category(err error) string {
+	if err == nil {
+		return ""
+	}
+	code := StatusCode(err)
+	categories.RLock()
+	category, ok := categories.m[code]
+	categories.RUnlock()
+	if ok {
+		return category
+	}
+	switch code {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusUnprocessableEntity:
+		return "validation"
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	}
+	switch {
+	case IsClientError(code):
+		return "client"
+	case IsServerError(code):
+		return "server"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,44 @@
+package httperr
+
+import (
+	"net/http"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestCategory(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{New(http.StatusBadRequest, nil), "bad_request"},
+		{New(http.StatusUnauthorized, nil), "unauthorized"},
+		{New(http.StatusForbidden, nil), "forbidden"},
+		{New(http.StatusNotFound, nil), "not_found"},
+		{New(http.StatusConflict, nil), "conflict"},
+		{New(http.StatusUnprocessableEntity, nil), "validation"},
+		{New(http.StatusTooManyRequests, nil), "rate_limited"},
+		{New(http.StatusGone, nil), "client"},
+		{New(http.StatusBadGateway, nil), "server"},
+		{errors.New("Plain error"), "server"},
+		{New(http.StatusMovedPermanently, nil), "unknown"},
+	} {
+		if got := Category(tc.err); got != tc.want {
+			t.Errorf("Invalid category %q for %v, want %q", got, tc.err, tc.want)
+		}
+	}
+}
+
+func TestRegisterCategory(t *testing.T) {
+	defer func() {
+		categories.Lock()
+		delete(categories.m, http.StatusGone)
+		categories.Unlock()
+	}()
+	RegisterCategory(http.StatusGone, "not_found")
+	if got := Category(New(http.StatusGone, nil)); got != "not_found" {
+		t.Errorf("Invalid registered category %q, want %q", got, "not_found")
+	}
+}
@@ -0,0 +1,168 @@
+package httperr
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	errors "golang.org/x/xerrors"
+)
+
+// maxBodySize is the maximum number of bytes read from a body to create an error
+const maxBodySize = 64 << 10
+
+// readBody reads at most maxBodySize bytes from r
+func readBody(r io.Reader) ([]byte, error) {
+	return ioutil.ReadAll(io.LimitReader(r, maxBodySize))
+}
+
+// NewFromReader creates a new HTTP error using the contents of r as the message.
+// Like FromResponse it reads at most 64KB, the rest is left unread in r.
+func NewFromReader(code int, r io.Reader) (error, error) {
+	data, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	msg := strings.TrimSpace(string(data))
+	if msg == "" {
+		return New(code, nil), nil
+	}
+	return New(code, errors.New(msg)), nil
+}
+
+// Decoder creates HTTP errors from responses
+type Decoder struct {
+	// Malformed receives the raw body of responses that fail to parse
+	Malformed io.Writer
+	// Extract extracts the message from a JSON body before the default message fields are tried
+	Extract func(data []byte) (message string, ok bool)
+}
+
+var defaultDecoder = &Decoder{}
+
+// FromResponse creates a new HTTP error from a response using the default Decoder
+func FromResponse(r *http.Response) error {
+	return defaultDecoder.FromResponse(r)
+}
+
+// FromResponse creates a new HTTP error from a response.
+// At most 64KB of the response body are read.
+// A nil response results in an HTTP 500 error
+// and a response with a status code outside the 100-599 range in an HTTP 502 error.
+// A non-standard reason phrase in the response status is preserved.
+func (d *Decoder) FromResponse(r *http.Response) error {
+	if r == nil {
+		return InternalServerError(errors.New("Nil response"))
+	}
+	if r.StatusCode < http.StatusContinue || r.StatusCode > 599 {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		return New(http.StatusBadGateway, errors.Errorf("Invalid response status code %d", r.StatusCode))
+	}
+	err := d.fromResponse(r)
+	if e, ok := err.(*httpError); ok && e.code == r.StatusCode {
+		e.status = reasonPhrase(r)
+	}
+	return err
+}
+
+// StatusError creates a new HTTP error with the status code of a response without reading its body.
+// The body is left open for the caller to read and close.
+// Like FromResponse, a non-standard reason phrase is preserved.
+func StatusError(r *http.Response) error {
+	if r == nil {
+		return InternalServerError(errors.New("Nil response"))
+	}
+	if r.StatusCode < http.StatusContinue || r.StatusCode > 599 {
+		return New(http.StatusBadGateway, errors.Errorf("Invalid response status code %d", r.StatusCode))
+	}
+	return &httpError{code: r.StatusCode, status: reasonPhrase(r)}
+}
+
+// reasonPhrase returns the reason phrase of a response status
+// if it differs from the standard status text
+func reasonPhrase(r *http.Response) string {
+	phrase := strings.TrimPrefix(r.Status, strconv.Itoa(r.StatusCode))
+	phrase = strings.TrimSpace(phrase)
+	if phrase == http.StatusText(r.StatusCode) {
+		return ""
+	}
+	return phrase
+}
+
+func (d *Decoder) fromResponse(r *http.Response) error {
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+	defer r.Body.Close()
+	data, err := readBody(r.Body)
+	if err != nil {
+		return New(r.StatusCode, errors.Errorf("Failed to read response body: %q", err))
+	}
+	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediatype == "text/plain", mediatype == "text/html", mediatype == "text/xml":
+		return New(r.StatusCode, errors.New(string(data)))
+	case mediatype == "application/json", strings.HasSuffix(mediatype, "+json"):
+		fallthrough
+	default:
+		if d.Extract != nil {
+			if msg, ok := d.Extract(data); ok {
+				return New(r.StatusCode, errors.New(msg))
+			}
+		}
+		var tmp map[string]json.RawMessage
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			if d.Malformed != nil {
+				d.Malformed.Write(data)
+			}
+			return New(r.StatusCode, errors.Errorf("Error parsing response: %s", err))
+		}
+		if msg := messageField(tmp); msg != "" {
+			return New(r.StatusCode, errors.New(msg))
+		}
+		return New(r.StatusCode, nil)
+	}
+}
+
+// messageFields are the fields checked in order for the message of a JSON response
+var messageFields = []string{"message", "error", "detail", "msg", "title"}
+
+// messageField returns the first non empty string message field of a JSON object
+func messageField(obj map[string]json.RawMessage) string {
+	for _, name := range messageFields {
+		var msg string
+		if err := json.Unmarshal(obj[name], &msg); err == nil && msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// FromClientError creates an HTTP error from an error returned by http.Client when no response was received.
+// Timeouts result in HTTP 504 errors, canceled requests in HTTP 499 errors
+// and all other transport failures, like refused connections, DNS or TLS errors, in HTTP 502 errors.
+// The original error is wrapped.
+func FromClientError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(http.StatusGatewayTimeout, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return New(http.StatusGatewayTimeout, err)
+	case errors.Is(err, context.Canceled):
+		return clientClosedRequest(err)
+	default:
+		return New(http.StatusBadGateway, err)
+	}
+}
@@ -0,0 +1,75 @@
+package httperr
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// countingReader counts the bytes read from a reader
+type countingReader struct {
+	r      io.Reader
+	n      int
+	closed bool
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	c.closed = true
+	return nil
+}
+
+func FuzzFromResponse(f *testing.F) {
+	for _, seed := range []struct {
+		code        int
+		contentType string
+		body        []byte
+		nilBody     bool
+		nilHeader   bool
+	}{
+		{http.StatusNotFound, "application/json", nil, false, false},
+		{http.StatusBadRequest, "application/json", []byte(`{"message":"trunc`), false, false},
+		{http.StatusBadRequest, "application/json", []byte(`[{"message":"array"}]`), false, false},
+		{http.StatusBadRequest, "text/plain", []byte("invalid \xff\xfe utf-8"), false, false},
+		{http.StatusConflict, "application/problem+json", []byte(`{"detail":"Version mismatch"}`), false, false},
+		{http.StatusBadGateway, "", nil, true, false},
+		{http.StatusServiceUnavailable, "", []byte(`{"error":"down"}`), false, true},
+		{0, "", nil, false, false},
+		{1000, "text/html", []byte("<h1>Oops</h1>"), false, false},
+		{http.StatusInternalServerError, "application/json", bytes.Repeat([]byte(" "), maxBodySize+1), false, false},
+	} {
+		f.Add(seed.code, seed.contentType, seed.body, seed.nilBody, seed.nilHeader)
+	}
+	f.Fuzz(func(t *testing.T, code int, contentType string, body []byte, nilBody, nilHeader bool) {
+		r := &http.Response{StatusCode: code}
+		if !nilHeader {
+			r.Header = http.Header{"Content-Type": {contentType}}
+		}
+		var counter *countingReader
+		if !nilBody {
+			counter = &countingReader{r: bytes.NewReader(body)}
+			r.Body = counter
+		}
+		err := FromResponse(r)
+		if err == nil {
+			t.Fatal("FromResponse returned nil")
+		}
+		if got := StatusCode(err); got < 100 || got > 599 {
+			t.Fatalf("Invalid status code %d", got)
+		}
+		if counter != nil {
+			if counter.n > maxBodySize {
+				t.Fatalf("Read %d bytes, want at most %d", counter.n, maxBodySize)
+			}
+			if !counter.closed {
+				t.Fatal("Body was not closed")
+			}
+		}
+	})
+}
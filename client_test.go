@@ -0,0 +1,219 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestFromResponseNil(t *testing.T) {
+	err := FromResponse(nil)
+	if err == nil {
+		t.Fatal("Expected an error for a nil response")
+	}
+	if code := StatusCode(err); code != http.StatusInternalServerError {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusInternalServerError)
+	}
+}
+
+func TestFromResponseReasonPhrase(t *testing.T) {
+	for _, tc := range []struct {
+		status string
+		code   int
+		want   string
+	}{
+		{"520 Origin Error", 520, "520 Origin Error"},
+		{"404 Gone Fishing", http.StatusNotFound, "404 Gone Fishing"},
+		{"404 Not Found", http.StatusNotFound, "404 Not Found"},
+		{"", http.StatusNotFound, "404 Not Found"},
+	} {
+		err := FromResponse(&http.Response{
+			Status:     tc.status,
+			StatusCode: tc.code,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		})
+		if got := err.Error(); !strings.HasPrefix(got, tc.want) {
+			t.Errorf("Invalid error %q for status %q, want prefix %q", got, tc.status, tc.want)
+		}
+	}
+}
+
+// jsonResponse creates a response with a JSON body
+func jsonResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestFromResponseMessageFields(t *testing.T) {
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{`{"message":"From message","error":"From error"}`, "From message"},
+		{`{"message":"","error":"From error"}`, "From error"},
+		{`{"detail":"From detail","title":"From title"}`, "From detail"},
+		{`{"msg":"From msg"}`, "From msg"},
+		{`{"title":"From title"}`, "From title"},
+		{`{"message":42,"title":"From title"}`, "From title"},
+		{`{"code":"E42"}`, "Bad Request"},
+	} {
+		err := FromResponse(jsonResponse(http.StatusBadRequest, tc.body))
+		if got := Message(err); got != tc.want {
+			t.Errorf("Invalid message %q for %s, want %q", got, tc.body, tc.want)
+		}
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	err, rerr := NewFromReader(http.StatusBadGateway, strings.NewReader("  exit status 1\n"))
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	if got := Message(err); got != "exit status 1" {
+		t.Errorf("Invalid message %q, want %q", got, "exit status 1")
+	}
+	if code := StatusCode(err); code != http.StatusBadGateway {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusBadGateway)
+	}
+
+	r := strings.NewReader(strings.Repeat("x", maxBodySize+100))
+	err, rerr = NewFromReader(http.StatusBadGateway, r)
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	if got := len(Message(err)); got != maxBodySize {
+		t.Errorf("Invalid message length %d, want %d", got, maxBodySize)
+	}
+	if r.Len() != 100 {
+		t.Errorf("Invalid unread length %d, want 100", r.Len())
+	}
+
+	err, _ = NewFromReader(http.StatusBadGateway, strings.NewReader(""))
+	if got := Message(err); got != "Bad Gateway" {
+		t.Errorf("Invalid message %q for an empty reader, want the status text", got)
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFromClientError(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"deadline", &url.Error{Op: "Get", URL: "http://example.com", Err: context.DeadlineExceeded}, http.StatusGatewayTimeout},
+		{"timeout", &url.Error{Op: "Get", URL: "http://example.com", Err: timeoutError{}}, http.StatusGatewayTimeout},
+		{"canceled", &url.Error{Op: "Get", URL: "http://example.com", Err: context.Canceled}, StatusClientClosedRequest},
+		{"refused", &url.Error{Op: "Get", URL: "http://example.com", Err: refused}, http.StatusBadGateway},
+		{"dns", &net.DNSError{Err: "no such host", Name: "example.invalid"}, http.StatusBadGateway},
+		{"tls", x509.UnknownAuthorityError{}, http.StatusBadGateway},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := FromClientError(tc.err)
+			if code := StatusCode(err); code != tc.want {
+				t.Errorf("Invalid status code %d, want %d", code, tc.want)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Error %v does not wrap %v", err, tc.err)
+			}
+		})
+	}
+	if err := FromClientError(nil); err != nil {
+		t.Errorf("Invalid error %v for nil, want nil", err)
+	}
+}
+
+func TestDecoderMalformed(t *testing.T) {
+	var sink bytes.Buffer
+	d := &Decoder{Malformed: &sink}
+	const body = `{"message": "truncated`
+	err := d.FromResponse(jsonResponse(http.StatusBadGateway, body))
+	if code := StatusCode(err); code != http.StatusBadGateway {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusBadGateway)
+	}
+	if sink.String() != body {
+		t.Errorf("Invalid malformed body %q, want %q", sink.String(), body)
+	}
+	sink.Reset()
+	d.FromResponse(jsonResponse(http.StatusBadGateway, `{"message":"ok"}`))
+	if sink.Len() != 0 {
+		t.Errorf("Valid body was written to the sink: %q", sink.String())
+	}
+}
+
+func TestFromResponseJSONSuffix(t *testing.T) {
+	for _, contentType := range []string{
+		"application/problem+json",
+		"application/vnd.api+json; charset=utf-8",
+		"application/json; charset=utf-8",
+	} {
+		r := jsonResponse(http.StatusConflict, `{"detail":"Version mismatch"}`)
+		r.Header.Set("Content-Type", contentType)
+		if got := Message(FromResponse(r)); got != "Version mismatch" {
+			t.Errorf("Invalid message %q for %s, want %q", got, contentType, "Version mismatch")
+		}
+	}
+}
+
+func TestDecoderExtract(t *testing.T) {
+	d := &Decoder{Extract: func(data []byte) (string, bool) {
+		var body struct {
+			Error struct {
+				Detail string `json:"detail"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil || body.Error.Detail == "" {
+			return "", false
+		}
+		return body.Error.Detail, true
+	}}
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{`{"error":{"detail":"Nested detail"},"message":"Top level"}`, "Nested detail"},
+		{`{"message":"Top level"}`, "Top level"},
+	} {
+		if got := Message(d.FromResponse(jsonResponse(http.StatusBadRequest, tc.body))); got != tc.want {
+			t.Errorf("Invalid message %q for %s, want %q", got, tc.body, tc.want)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	body := &countingReader{r: strings.NewReader("Upstream detail")}
+	r := &http.Response{Status: "503 Back Soon", StatusCode: http.StatusServiceUnavailable, Body: body}
+	err := StatusError(r)
+	if code := StatusCode(err); code != http.StatusServiceUnavailable {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if got := err.Error(); got != "503 Back Soon" {
+		t.Errorf("Invalid error %q, want %q", got, "503 Back Soon")
+	}
+	if body.n != 0 || body.closed {
+		t.Errorf("Body was touched: read %d bytes, closed %t", body.n, body.closed)
+	}
+	if code := StatusCode(StatusError(&http.Response{StatusCode: 42})); code != http.StatusBadGateway {
+		t.Errorf("Invalid status code %d for an invalid response code, want %d", code, http.StatusBadGateway)
+	}
+}
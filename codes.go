@@ -0,0 +1,52 @@
+package httperr
+
+import "net/http"
+
+// Error status codes.
+//
+// They are aliases of the net/http constants so they always stay in sync.
+// Use them to switch on StatusCode results without importing net/http,
+// code that already uses net/http can keep using its constants.
+const (
+	CodeBadRequest                   = http.StatusBadRequest
+	CodeUnauthorized                 = http.StatusUnauthorized
+	CodePaymentRequired              = http.StatusPaymentRequired
+	CodeForbidden                    = http.StatusForbidden
+	CodeNotFound                     = http.StatusNotFound
+	CodeMethodNotAllowed             = http.StatusMethodNotAllowed
+	CodeNotAcceptable                = http.StatusNotAcceptable
+	CodeProxyAuthRequired            = http.StatusProxyAuthRequired
+	CodeRequestTimeout               = http.StatusRequestTimeout
+	CodeConflict                     = http.StatusConflict
+	CodeGone                         = http.StatusGone
+	CodeLengthRequired               = http.StatusLengthRequired
+	CodePreconditionFailed           = http.StatusPreconditionFailed
+	CodeRequestEntityTooLarge        = http.StatusRequestEntityTooLarge
+	CodeRequestURITooLong            = http.StatusRequestURITooLong
+	CodeUnsupportedMediaType         = http.StatusUnsupportedMediaType
+	CodeRequestedRangeNotSatisfiable = http.StatusRequestedRangeNotSatisfiable
+	CodeExpectationFailed            = http.StatusExpectationFailed
+	CodeTeapot                       = http.StatusTeapot
+	CodeMisdirectedRequest           = http.StatusMisdirectedRequest
+	CodeUnprocessableEntity          = http.StatusUnprocessableEntity
+	CodeLocked                       = http.StatusLocked
+	CodeFailedDependency             = http.StatusFailedDependency
+	CodeTooEarly                     = http.StatusTooEarly
+	CodeUpgradeRequired              = http.StatusUpgradeRequired
+	CodePreconditionRequired         = http.StatusPreconditionRequired
+	CodeTooManyRequests              = http.StatusTooManyRequests
+	CodeRequestHeaderFieldsTooLarge  = http.StatusRequestHeaderFieldsTooLarge
+	CodeUnavailableForLegalReasons   = http.StatusUnavailableForLegalReasons
+
+	CodeInternalServerError           = http.StatusInternalServerError
+	CodeNotImplemented                = http.StatusNotImplemented
+	CodeBadGateway                    = http.StatusBadGateway
+	CodeServiceUnavailable            = http.StatusServiceUnavailable
+	CodeGatewayTimeout                = http.StatusGatewayTimeout
+	CodeHTTPVersionNotSupported       = http.StatusHTTPVersionNotSupported
+	CodeVariantAlsoNegotiates         = http.StatusVariantAlsoNegotiates
+	CodeInsufficientStorage           = http.StatusInsufficientStorage
+	CodeLoopDetected                  = http.StatusLoopDetected
+	CodeNotExtended                   = http.StatusNotExtended
+	CodeNetworkAuthenticationRequired = http.StatusNetworkAuthenticationRequired
+)
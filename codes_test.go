@@ -0,0 +1,61 @@
+package httperr
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCodes(t *testing.T) {
+	for code, want := range map[int]int{
+		CodeBadRequest:          400,
+		CodeNotFound:            404,
+		CodeTeapot:              418,
+		CodeTooManyRequests:     429,
+		CodeInternalServerError: 500,
+		CodeGatewayTimeout:      504,
+	} {
+		if code != want {
+			t.Errorf("Invalid code %d, want %d", code, want)
+		}
+		if http.StatusText(code) == "" {
+			t.Errorf("Code %d has no status text", code)
+		}
+	}
+}
+
+func TestCodesMatchNetHTTP(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "codes.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	ast.Inspect(f, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, name := range spec.Names {
+			sel, ok := spec.Values[i].(*ast.SelectorExpr)
+			if ok {
+				pkg, isIdent := sel.X.(*ast.Ident)
+				ok = isIdent && pkg.Name == "http"
+			}
+			if !ok {
+				t.Errorf("%s is not a net/http constant", name.Name)
+				continue
+			}
+			if want := "Status" + strings.TrimPrefix(name.Name, "Code"); sel.Sel.Name != want {
+				t.Errorf("%s is http.%s, want http.%s", name.Name, sel.Sel.Name, want)
+			}
+			n++
+		}
+		return false
+	})
+	if n == 0 {
+		t.Error("No codes found")
+	}
+}
@@ -0,0 +1,30 @@
+package httperr
+
+import (
+	"net/http"
+	"strings"
+
+	errors "golang.org/x/xerrors"
+)
+
+// CheckIfMatch checks the If-Match header of a request against the current entity tag of a resource.
+// It returns an HTTP 412 error if the header is set and none of its entity tags match etag
+// using the strong comparison of RFC 7232, so weak tags never match.
+// The etag can be given with or without quotes.
+// A "*" header matches any resource with a non empty etag.
+func CheckIfMatch(r *http.Request, etag string) error {
+	header := strings.Join(r.Header["If-Match"], ",")
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+	if etag != "" && !strings.HasPrefix(etag, `"`) && !strings.HasPrefix(etag, "W/") {
+		etag = `"` + etag + `"`
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" && etag != "" || tag == etag && !strings.HasPrefix(tag, "W/") {
+			return nil
+		}
+	}
+	return PreconditionFailed(errors.New("Entity tag does not match"))
+}
@@ -0,0 +1,32 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	for _, tc := range []struct {
+		ifMatch string
+		etag    string
+		want    int
+	}{
+		{"", `"v1"`, 0},
+		{`"v1"`, `"v1"`, 0},
+		{`"v1"`, "v1", 0},
+		{`"v0", "v1"`, `"v1"`, 0},
+		{"*", `"v1"`, 0},
+		{`"v2"`, `"v1"`, http.StatusPreconditionFailed},
+		{`W/"v1"`, `W/"v1"`, http.StatusPreconditionFailed},
+		{"*", "", http.StatusPreconditionFailed},
+	} {
+		r := httptest.NewRequest(http.MethodPut, "/", nil)
+		if tc.ifMatch != "" {
+			r.Header.Set("If-Match", tc.ifMatch)
+		}
+		if code := StatusCode(CheckIfMatch(r, tc.etag)); code != tc.want {
+			t.Errorf("CheckIfMatch(%q, %q) status code %d, want %d", tc.ifMatch, tc.etag, code, tc.want)
+		}
+	}
+}
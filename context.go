@@ -0,0 +1,66 @@
+package httperr
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+// StatusClientClosedRequest is the non-standard status code for requests canceled by the client
+const StatusClientClosedRequest = 499
+
+// FromContext creates an HTTP error from a context error.
+// Deadline errors result in HTTP 504 and cancellation in HTTP 499 errors.
+// It returns nil if the context is not done.
+func FromContext(ctx context.Context) error {
+	err := ctx.Err()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(http.StatusGatewayTimeout, err)
+	default:
+		return clientClosedRequest(err)
+	}
+}
+
+// clientClosedRequest creates an HTTP 499 error
+func clientClosedRequest(err error) error {
+	return &httpError{
+		code:   StatusClientClosedRequest,
+		status: "Client Closed Request",
+		err:    err,
+	}
+}
+
+// FromContextSince creates an HTTP error from a context error like FromContext
+// recording the time elapsed since start.
+func FromContextSince(ctx context.Context, start time.Time) error {
+	err := FromContext(ctx)
+	if e, ok := err.(*httpError); ok {
+		e.elapsed = time.Since(start)
+	}
+	return err
+}
+
+// Elapsed returns the time waited before the error occurred.
+// It is only available for errors created with FromContextSince.
+func (e *httpError) Elapsed() time.Duration {
+	return e.elapsed
+}
+
+type errorKey struct{}
+
+// WithError returns a copy of ctx carrying err.
+// Middleware can use it to defer rendering errors until inner handlers return.
+func WithError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorKey{}, err)
+}
+
+// ErrorFrom returns the error stored in ctx with WithError or nil
+func ErrorFrom(ctx context.Context) error {
+	err, _ := ctx.Value(errorKey{}).(error)
+	return err
+}
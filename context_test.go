@@ -0,0 +1,59 @@
+package httperr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFromContextSince(t *testing.T) {
+	start := time.Now().Add(-1500 * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.Background(), start)
+	defer cancel()
+	err := FromContextSince(ctx, start)
+	if code := StatusCode(err); code != http.StatusGatewayTimeout {
+		t.Fatalf("Invalid status code %d, want %d", code, http.StatusGatewayTimeout)
+	}
+	elapsed := err.(*httpError).Elapsed()
+	if elapsed < 1500*time.Millisecond {
+		t.Errorf("Invalid elapsed time %s, want at least 1.5s", elapsed)
+	}
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		want bool
+	}{
+		{"enabled", &Responder{ReportElapsed: true}, true},
+		{"disabled", &Responder{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, err)
+			var body map[string]interface{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			ms, ok := body["timeoutMs"].(float64)
+			if ok != tc.want {
+				t.Fatalf("Invalid timeoutMs presence %t, want %t in %s", ok, tc.want, rr.Body.Bytes())
+			}
+			if ok && time.Duration(ms)*time.Millisecond != elapsed.Truncate(time.Millisecond) {
+				t.Errorf("Invalid timeoutMs %v, want %d", ms, elapsed/time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestErrorFrom(t *testing.T) {
+	if err := ErrorFrom(context.Background()); err != nil {
+		t.Errorf("Invalid error %v from an empty context, want nil", err)
+	}
+	want := NotFound(nil)
+	ctx := WithError(context.Background(), want)
+	if got := ErrorFrom(ctx); got != want {
+		t.Errorf("Invalid error %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package httperr
+
+import (
+	"net/http"
+	"os/exec"
+	"strings"
+
+	errors "golang.org/x/xerrors"
+)
+
+// maxStderrSize is the maximum number of stderr bytes kept as the message of a command failure
+const maxStderrSize = 1 << 10
+
+// FromExitError converts a failed command to an HTTP 500 error using its stderr as the message
+func FromExitError(err error) error {
+	return FromExitErrorWith(http.StatusInternalServerError, err)
+}
+
+// FromExitErrorWith converts a failed command to an HTTP error with a status code.
+// The message is the end of the command's stderr, or the exit status if stderr was not captured.
+// The *exec.ExitError is preserved in the chain. Other errors are returned as is.
+func FromExitErrorWith(code int, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	msg := strings.TrimSpace(string(exitErr.Stderr))
+	if len(msg) > maxStderrSize {
+		msg = msg[len(msg)-maxStderrSize:]
+	}
+	if msg == "" {
+		msg = exitErr.Error()
+	}
+	return New(code, &commandError{msg: msg, err: err})
+}
+
+// commandError replaces the message of a command failure with its stderr
+type commandError struct {
+	msg string
+	err error
+}
+
+func (e *commandError) Error() string {
+	return e.msg
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,37 @@
+package httperr
+
+import (
+	"net/http"
+	"os/exec"
+	"strings"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestFromExitError(t *testing.T) {
+	exitErr := &exec.ExitError{Stderr: []byte("fatal: not a git repository\n")}
+	err := FromExitError(errors.Errorf("Running git: %w", exitErr))
+	if code := StatusCode(err); code != http.StatusInternalServerError {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusInternalServerError)
+	}
+	if got := Message(err); got != "fatal: not a git repository" {
+		t.Errorf("Invalid message %q", got)
+	}
+	var target *exec.ExitError
+	if !errors.As(err, &target) || target != exitErr {
+		t.Error("Exit error is not in the chain")
+	}
+
+	long := &exec.ExitError{Stderr: []byte(strings.Repeat("x", maxStderrSize) + "tail")}
+	if got := Message(FromExitErrorWith(http.StatusBadGateway, long)); len(got) != maxStderrSize || !strings.HasSuffix(got, "tail") {
+		t.Errorf("Invalid truncated message of length %d", len(got))
+	}
+	if got := Message(FromExitError(&exec.ExitError{})); got == "" {
+		t.Error("Empty message without stderr")
+	}
+	other := errors.New("Not a command error")
+	if err := FromExitError(other); err != other {
+		t.Errorf("Invalid error %v, want it returned as is", err)
+	}
+}
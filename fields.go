@@ -0,0 +1,84 @@
+package httperr
+
+import "fmt"
+
+// WithField returns a copy of err with a detail field.
+// Fields are included in verbose error bodies and logs but not in standard bodies.
+// It returns nil if err is nil.
+func WithField(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	fields := make(map[string]interface{}, len(e.fields)+1)
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	e.fields = fields
+	return e
+}
+
+// Fields merges the detail fields of all errors in the chain.
+// Fields of outer errors take precedence over inner ones.
+func Fields(err error) map[string]interface{} {
+	return merge(err, func(e *httpError) map[string]interface{} {
+		return e.fields
+	})
+}
+
+// WithExtension returns a copy of err with an extension member.
+// Extensions are added to the top level of error bodies.
+// It returns nil if err is nil.
+func WithExtension(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	extensions := make(map[string]interface{}, len(e.extensions)+1)
+	for k, v := range e.extensions {
+		extensions[k] = v
+	}
+	extensions[key] = value
+	e.extensions = extensions
+	return e
+}
+
+// Extensions merges the extension members of all errors in the chain.
+// Extensions of outer errors take precedence over inner ones.
+func Extensions(err error) map[string]interface{} {
+	return merge(err, func(e *httpError) map[string]interface{} {
+		return e.extensions
+	})
+}
+
+// merge merges maps of all HTTP errors in the chain, outer errors taking precedence
+func merge(err error, values func(e *httpError) map[string]interface{}) map[string]interface{} {
+	var m map[string]interface{}
+	walk(err, func(err error) {
+		e, ok := err.(*httpError)
+		if !ok {
+			return
+		}
+		for k, v := range values(e) {
+			if m == nil {
+				m = make(map[string]interface{})
+			}
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+	})
+	return m
+}
+
+// locations returns the "file:line" locations of all errors in the chain that have one
+func locations(err error) []string {
+	var stack []string
+	walk(err, func(err error) {
+		if e, ok := err.(*httpError); ok && e.file != "" {
+			stack = append(stack, fmt.Sprintf("%s:%d", e.file, e.line))
+		}
+	})
+	return stack
+}
@@ -1,5 +1,5 @@
 module github.com/alxarch/httperr
 
-go 1.12
+go 1.19
 
 require golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
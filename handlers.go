@@ -0,0 +1,55 @@
+package httperr
+
+import (
+	"net/http"
+	"sync"
+)
+
+var handlers = struct {
+	sync.RWMutex
+	m map[int]func(w http.ResponseWriter, r *http.Request, err error)
+}{m: make(map[int]func(w http.ResponseWriter, r *http.Request, err error))}
+
+// RegisterHandler registers a handler responding to errors with a status code.
+// Respond and the Handler middleware dispatch errors to it instead of rendering them,
+// which is useful for custom error pages.
+func RegisterHandler(code int, h func(w http.ResponseWriter, r *http.Request, err error)) {
+	handlers.Lock()
+	defer handlers.Unlock()
+	handlers.m[code] = h
+}
+
+// RegisterHandlers registers handlers for several status codes like RegisterHandler
+func RegisterHandlers(m map[int]func(w http.ResponseWriter, r *http.Request, err error)) {
+	handlers.Lock()
+	defer handlers.Unlock()
+	for code, h := range m {
+		handlers.m[code] = h
+	}
+}
+
+// ResetHandlers removes all registered status handlers
+func ResetHandlers() {
+	handlers.Lock()
+	defer handlers.Unlock()
+	handlers.m = make(map[int]func(w http.ResponseWriter, r *http.Request, err error))
+}
+
+func lookupHandler(code int) func(w http.ResponseWriter, r *http.Request, err error) {
+	handlers.RLock()
+	defer handlers.RUnlock()
+	return handlers.m[code]
+}
+
+// dispatch passes err to the handler registered for its status code after setting the error headers.
+// It reports whether a handler was found.
+func (rs *Responder) dispatch(w http.ResponseWriter, r *http.Request, err error) bool {
+	h := lookupHandler(rs.statusCode(err))
+	if h == nil {
+		return false
+	}
+	rs.recordError(err)
+	rs.setErrorHeaders(w, err)
+	h(w, r, err)
+	return true
+}
@@ -0,0 +1,42 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterHandlers(t *testing.T) {
+	defer ResetHandlers()
+	page := func(body string) func(w http.ResponseWriter, r *http.Request, err error) {
+		return func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(StatusCode(err))
+			io.WriteString(w, body)
+		}
+	}
+	RegisterHandlers(map[int]func(w http.ResponseWriter, r *http.Request, err error){
+		http.StatusGone:                       page("gone page"),
+		http.StatusUnavailableForLegalReasons: page("legal page"),
+	})
+	for code, want := range map[int]string{
+		http.StatusGone:                       "gone page",
+		http.StatusUnavailableForLegalReasons: "legal page",
+	} {
+		rr := httptest.NewRecorder()
+		Respond(rr, httptest.NewRequest(http.MethodGet, "/", nil), New(code, nil))
+		if rr.Code != code || rr.Body.String() != want {
+			t.Errorf("Invalid response %d %q, want %d %q", rr.Code, rr.Body.String(), code, want)
+		}
+		if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("Error headers were not set for %d", code)
+		}
+	}
+
+	ResetHandlers()
+	rr := httptest.NewRecorder()
+	Respond(rr, httptest.NewRequest(http.MethodGet, "/", nil), New(http.StatusGone, nil))
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Invalid content type %q after reset, want %q", got, "application/json")
+	}
+}
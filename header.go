@@ -0,0 +1,182 @@
+package httperr
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+// WithHeader returns a copy of err with an HTTP header added to the response.
+// If err is not an HTTP error it is wrapped with its resolved status code.
+// It returns nil if err is nil.
+func WithHeader(err error, key, value string) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	if e.header == nil {
+		e.header = make(http.Header)
+	}
+	e.header.Add(key, value)
+	return e
+}
+
+// CollectHeaders merges the HTTP headers of all errors in the chain.
+// Headers of outer errors take precedence over inner ones.
+func CollectHeaders(err error) http.Header {
+	header := make(http.Header)
+	walk(err, func(err error) {
+		e, ok := err.(*httpError)
+		if !ok {
+			return
+		}
+		for k, v := range e.header {
+			if _, ok := header[k]; !ok {
+				header[k] = append([]string(nil), v...)
+			}
+		}
+	})
+	return header
+}
+
+// WithRetryAfter returns a copy of err that sends a Retry-After header.
+// The delay is sent in seconds, rounded up.
+// It returns nil if err is nil.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	e.retryAfter = d
+	e.retryAt = time.Time{}
+	return e
+}
+
+// RetryAfter returns the Retry-After delay of the outermost error in the chain that has one.
+// Errors retrying at a time, ie from TooManyRequestsWith, return the delay from now.
+func RetryAfter(err error) time.Duration {
+	return retryAfter(err, time.Now())
+}
+
+// retryAfter resolves the Retry-After delay of an error at now
+func retryAfter(err error, now time.Time) time.Duration {
+	for ; err != nil; err = errors.Unwrap(err) {
+		e, ok := err.(*httpError)
+		switch {
+		case !ok:
+		case e.retryAfter > 0:
+			return e.retryAfter
+		case !e.retryAt.IsZero():
+			return e.retryAt.Sub(now)
+		}
+	}
+	return 0
+}
+
+// withRetryAt returns a copy of err that sends a Retry-After header until t.
+// The delay is resolved when the response is sent.
+func withRetryAt(err error, t time.Time) error {
+	e := with(err)
+	e.retryAfter = 0
+	e.retryAt = t
+	return e
+}
+
+// TooManyRequestsWith creates an HTTP 429 error with rate limit headers.
+// It sets X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset
+// as a unix timestamp and Retry-After until reset, computed when the response is sent.
+func TooManyRequestsWith(limit, remaining int, reset time.Time) error {
+	err := TooManyRequests(nil)
+	err = WithHeader(err, "X-RateLimit-Limit", strconv.Itoa(limit))
+	err = WithHeader(err, "X-RateLimit-Remaining", strconv.Itoa(remaining))
+	err = WithHeader(err, "X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return withRetryAt(err, reset)
+}
+
+// Maintenance creates an HTTP 503 error for planned maintenance ending at until.
+// It sets Retry-After until the end of maintenance, computed when the response is sent, and a "maintenanceUntil" extension in RFC 3339 format.
+func Maintenance(until time.Time, msg string) error {
+	err := ServiceUnavailable(errors.New(msg))
+	err = WithExtension(err, "maintenanceUntil", until.UTC().Format(time.RFC3339))
+	return withRetryAt(err, until)
+}
+
+// WithSunset returns a copy of err that sends a Sunset header (RFC 8594)
+// announcing the retirement of the endpoint at t.
+// It returns nil if err is nil.
+func WithSunset(err error, t time.Time) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	if e.header == nil {
+		e.header = make(http.Header)
+	}
+	e.header.Set("Sunset", t.UTC().Format(http.TimeFormat))
+	return e
+}
+
+// WithWarning returns a copy of err that adds an RFC 7234 Warning header,
+// ie WithWarning(err, 110, "Response is Stale") sends `Warning: 110 - "Response is Stale"`.
+// The warn-agent is always "-". Use SetWarning for successful responses.
+// It returns nil if err is nil.
+func WithWarning(err error, code int, text string) error {
+	return WithHeader(err, "Warning", warning(code, text))
+}
+
+// SetWarning adds an RFC 7234 Warning header to a response in the same format as WithWarning.
+// It is meant for successful responses, ie stale data served because an upstream failed.
+func SetWarning(w http.ResponseWriter, code int, text string) {
+	w.Header().Add("Warning", warning(code, text))
+}
+
+// warning formats a Warning header value
+func warning(code int, text string) string {
+	return fmt.Sprintf("%03d - %s", code, quoteString(text))
+}
+
+// quoteString formats s as an HTTP quoted-string
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r', '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// WithCookie returns a copy of err that sets a cookie on the response.
+// Unlike WithHeader it supports sending multiple cookies, ie to clear them on an HTTP 401 error.
+// It returns nil if err is nil.
+func WithCookie(err error, c *http.Cookie) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	e.cookies = append(e.cookies, c)
+	return e
+}
+
+// Cookies returns the cookies of all errors in the chain, outermost first
+func Cookies(err error) []*http.Cookie {
+	var cookies []*http.Cookie
+	walk(err, func(err error) {
+		if e, ok := err.(*httpError); ok {
+			cookies = append(cookies, e.cookies...)
+		}
+	})
+	return cookies
+}
@@ -0,0 +1,166 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestCollectHeaders(t *testing.T) {
+	inner := WithHeader(New(http.StatusUnauthorized, errors.New("No token")), "WWW-Authenticate", `Bearer realm="api"`)
+	inner = WithHeader(inner, "X-Layer", "inner")
+	outer := WithHeader(New(http.StatusForbidden, inner), "X-Request-Id", "abc")
+	outer = WithHeader(outer, "X-Layer", "outer")
+	h := CollectHeaders(outer)
+	for key, want := range map[string]string{
+		"WWW-Authenticate": `Bearer realm="api"`,
+		"X-Request-Id":     "abc",
+		"X-Layer":          "outer",
+	} {
+		if got := h.Get(key); got != want {
+			t.Errorf("Invalid %s header %q, want %q", key, got, want)
+		}
+	}
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, outer)
+	if got := rr.Header().Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+		t.Errorf("Inner header was not sent: %q", got)
+	}
+}
+
+func TestTooManyRequestsWith(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(30 * time.Second)
+	err := TooManyRequestsWith(100, 0, reset)
+	rs := &Responder{Now: func() time.Time { return now }}
+	rr := httptest.NewRecorder()
+	rs.RespondJSON(rr, err)
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusTooManyRequests)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Invalid Retry-After %q, want 30 seconds", got)
+	}
+	now = now.Add(20 * time.Second)
+	rr = httptest.NewRecorder()
+	rs.RespondJSON(rr, err)
+	if got := rr.Header().Get("Retry-After"); got != "10" {
+		t.Errorf("Invalid Retry-After %q when sent later, want 10 seconds", got)
+	}
+	now = reset
+	rr = httptest.NewRecorder()
+	rs.RespondJSON(rr, err)
+	if got := rr.Header().Get("Retry-After"); got != "" {
+		t.Errorf("Invalid Retry-After %q after reset, want none", got)
+	}
+	if d := RetryAfter(TooManyRequestsWith(100, 0, time.Now().Add(time.Hour))); d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("Invalid RetryAfter %s, want an hour from now", d)
+	}
+	for key, want := range map[string]string{
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+	} {
+		if got := rr.Header().Get(key); got != want {
+			t.Errorf("Invalid %s header %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestRetryAfterFormat(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	err := WithRetryAfter(ServiceUnavailable(nil), 90*time.Second+time.Millisecond)
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		want string
+	}{
+		{"seconds", &Responder{Now: func() time.Time { return now }}, "91"},
+		{"date", &Responder{RetryAfterDate: true, Now: func() time.Time { return now }}, "Fri, 01 Mar 2024 12:01:30 GMT"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, err)
+			if got := rr.Header().Get("Retry-After"); got != tc.want {
+				t.Errorf("Invalid Retry-After %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWithSunset(t *testing.T) {
+	sunset := time.Date(2025, time.June, 30, 23, 59, 59, 0, time.FixedZone("EEST", 3*60*60))
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, WithSunset(New(http.StatusGone, nil), sunset))
+	if got, want := rr.Header().Get("Sunset"), "Mon, 30 Jun 2025 20:59:59 GMT"; got != want {
+		t.Errorf("Invalid Sunset header %q, want %q", got, want)
+	}
+	if rr.Code != http.StatusGone {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusGone)
+	}
+}
+
+func TestWithCookie(t *testing.T) {
+	err := WithCookie(New(http.StatusUnauthorized, nil), &http.Cookie{Name: "session", MaxAge: -1})
+	err = WithCookie(err, &http.Cookie{Name: "csrf", MaxAge: -1})
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, err)
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Name != "session" || cookies[1].Name != "csrf" {
+		t.Fatalf("Invalid cookies %v, want session and csrf", rr.Header()["Set-Cookie"])
+	}
+	for _, c := range cookies {
+		if c.MaxAge != -1 {
+			t.Errorf("Cookie %s is not cleared", c.Name)
+		}
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(10 * time.Minute)
+	rs := &Responder{Now: func() time.Time { return now }}
+	rr := httptest.NewRecorder()
+	rs.RespondJSON(rr, Maintenance(until, "Upgrading database"))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "600" {
+		t.Errorf("Invalid Retry-After %q, want 600 seconds", got)
+	}
+	var body struct {
+		Message          string `json:"message"`
+		MaintenanceUntil string `json:"maintenanceUntil"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Message != "Upgrading database" || body.MaintenanceUntil != until.UTC().Format(time.RFC3339) {
+		t.Errorf("Invalid body %s", rr.Body.Bytes())
+	}
+}
+
+func TestWarning(t *testing.T) {
+	rr := httptest.NewRecorder()
+	SetWarning(rr, 199, `Serving "stale" data`)
+	SetWarning(rr, 110, "Response is Stale")
+	RespondJSON(rr, map[string]string{"id": "42"})
+	if rr.Code != http.StatusOK {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusOK)
+	}
+	want := []string{`199 - "Serving \"stale\" data"`, `110 - "Response is Stale"`}
+	if got := rr.Header()["Warning"]; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Invalid Warning headers %q, want %q", got, want)
+	}
+
+	rr = httptest.NewRecorder()
+	RespondJSON(rr, WithWarning(ServiceUnavailable(nil), 111, "Revalidation Failed"))
+	if got := rr.Header().Get("Warning"); got != `111 - "Revalidation Failed"` {
+		t.Errorf("Invalid Warning header %q", got)
+	}
+}
@@ -1,11 +1,13 @@
 package httperr
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"mime"
+	"io"
 	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 
 	errors "golang.org/x/xerrors"
 )
@@ -16,30 +18,343 @@ type StatusCoder interface {
 }
 
 type httpError struct {
-	code int
-	err  error
+	code       int
+	err        error
+	file       string
+	line       int
+	header     http.Header
+	status     string
+	elapsed    time.Duration
+	retryAfter time.Duration
+	retryAt    time.Time
+	fields     map[string]interface{}
+	cookies    []*http.Cookie
+	extensions map[string]interface{}
+	visibility visibility
+	typeURI    string
+	message    string
+	stack      []byte
+	// pooled marks errors obtained with Acquire
+	pooled bool
 }
 
 func (e *httpError) Error() string {
-	status := http.StatusText(e.code)
+	status := e.statusText()
 	if e.err == nil {
 		return fmt.Sprintf("%d %s", e.code, status)
 	}
 	return fmt.Sprintf("%d %s: %q", e.code, status, e.err)
 }
+
+// Message returns the message of the wrapped error or the status text if there is none.
+// If the wrapped error is also an HTTP error its message is used instead of its Error() string.
+func (e *httpError) Message() string {
+	if e.message != "" {
+		return e.message
+	}
+	switch cause := e.err.(type) {
+	case nil:
+		return e.statusText()
+	case *httpError:
+		return cause.Message()
+	default:
+		return cause.Error()
+	}
+}
+
+// statusText returns the reason phrase of the error's status
+func (e *httpError) statusText() string {
+	if e.status != "" {
+		return e.status
+	}
+	return http.StatusText(e.code)
+}
+
 func (e *httpError) StatusCode() int {
 	return e.code
 }
+
+// HTTPStatus returns the status code for libraries
+// sniffing for an `HTTPStatus() int` method
+func (e *httpError) HTTPStatus() int {
+	return e.code
+}
+
+// ResponseStatus returns the status code for libraries
+// sniffing for a `ResponseStatus() int` method
+func (e *httpError) ResponseStatus() int {
+	return e.code
+}
 func (e *httpError) Unwrap() error {
 	return e.err
 }
 
-// New creates a new HTTP error
+// Clone returns a deep copy of err if it is an HTTP error and err as is otherwise.
+//
+// Errors shared between requests, such as package level sentinels,
+// must never be modified in place. The With* builders always return copies,
+// Clone is for deriving per request variants explicitly.
+// Headers, cookies, fields and extensions of the copy do not share memory with err
+// but the field and extension values themselves are not copied.
+func Clone(err error) error {
+	if e, ok := err.(*httpError); ok && e != nil {
+		return e.clone()
+	}
+	return err
+}
+
+// clone returns a deep copy of the error
+func (e *httpError) clone() *httpError {
+	c := *e
+	if e.header != nil {
+		c.header = make(http.Header, len(e.header))
+		for k, v := range e.header {
+			c.header[k] = append([]string(nil), v...)
+		}
+	}
+	if e.cookies != nil {
+		c.cookies = make([]*http.Cookie, len(e.cookies))
+		for i, cookie := range e.cookies {
+			if cookie == nil {
+				continue
+			}
+			cc := *cookie
+			cc.Unparsed = append([]string(nil), cookie.Unparsed...)
+			c.cookies[i] = &cc
+		}
+	}
+	c.fields = copyMap(e.fields)
+	c.extensions = copyMap(e.extensions)
+	c.pooled = false
+	return &c
+}
+
+// copyMap returns a shallow copy of m or nil if m is nil
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// ReplaceMessage returns a copy of e whose cause is replaced by a new error with msg.
+// The status code and metadata like headers, cookies, fields and Retry-After are kept
+// but the original cause is no longer in the chain.
+func (e *httpError) ReplaceMessage(msg string) *httpError {
+	c := e.clone()
+	c.err = errors.New(msg)
+	c.message = ""
+	return c
+}
+
+// with returns a modifiable copy of err if it is an HTTP error
+// or wraps it in a new one with the resolved status code.
+func with(err error) *httpError {
+	if e, ok := err.(*httpError); ok {
+		return e.clone()
+	}
+	code := StatusCode(err)
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	return &httpError{code: code, err: err}
+}
+
+// Location returns the file and line where the error was created.
+// It is only available for errors created with NewCaller.
+func (e *httpError) Location() (file string, line int) {
+	return e.file, e.line
+}
+
+// Format implements fmt.Formatter.
+// The %+v verb also prints the location where the error was created and the stack of recovered panics.
+// Other verbs format the error message like a string, keeping flags, width and precision.
+func (e *httpError) Format(s fmt.State, verb rune) {
+	if verb != 'v' || !s.Flag('+') {
+		fmt.Fprintf(s, fmt.FormatString(s, verb), e.Error())
+		return
+	}
+	io.WriteString(s, e.Error())
+	if file, line := e.Location(); file != "" {
+		fmt.Fprintf(s, "\n    %s:%d", file, line)
+	}
+	if len(e.stack) > 0 {
+		fmt.Fprintf(s, "\n%s", e.stack)
+	}
+}
+
+// causeTypeError appends the type of the innermost cause of an error to its %+v format,
+// ie "(type: *pq.Error)". See Responder.LogCauseType.
+type causeTypeError struct {
+	err error
+}
+
+func (e *causeTypeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *causeTypeError) Unwrap() error {
+	return e.err
+}
+
+// Format implements fmt.Formatter.
+// The type is added to the first line so that locations and stacks stay below it.
+func (e *causeTypeError) Format(s fmt.State, verb rune) {
+	cause := rootCause(e.err)
+	if verb != 'v' || !s.Flag('+') || cause == nil {
+		fmt.Fprintf(s, fmt.FormatString(s, verb), e.err)
+		return
+	}
+	out := fmt.Sprintf("%+v", e.err)
+	first, rest := out, ""
+	if i := strings.IndexByte(out, '\n'); i != -1 {
+		first, rest = out[:i], out[i:]
+	}
+	fmt.Fprintf(s, "%s (type: %s)%s", first, reflect.TypeOf(cause), rest)
+}
+
+// rootCause returns the innermost error in the chain of err
+// or nil if it is an HTTP error without a cause
+func rootCause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			break
+		}
+		err = next
+	}
+	if _, ok := err.(*httpError); ok {
+		return nil
+	}
+	return err
+}
+
+// StatusCode resolves the HTTP status code of an error.
+// It returns the code of the first StatusCoder in the error chain,
+// http.StatusInternalServerError if there is none and 0 if err is nil.
+func StatusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return coder.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// StatusCodes returns the status codes of all StatusCoder errors in the chain, outermost first.
+// Errors joined with an `Unwrap() []error` method are walked in order.
+func StatusCodes(err error) []int {
+	var codes []int
+	walk(err, func(err error) {
+		if coder, ok := err.(StatusCoder); ok {
+			codes = append(codes, coder.StatusCode())
+		}
+	})
+	return codes
+}
+
+// walk calls fn for each error in the chain depth first
+func walk(err error, fn func(err error)) {
+	for err != nil {
+		fn(err)
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				walk(err, fn)
+			}
+			return
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// IsHTTPError checks if the error chain contains an error created by this package
+// as opposed to a StatusCoder from another library
+func IsHTTPError(err error) bool {
+	var e *httpError
+	return errors.As(err, &e)
+}
+
+// Normalize converts errors with a foreign StatusCoder in their chain to HTTP errors with the same code.
+// HTTP errors, errors without a StatusCoder and nil are returned as is.
+// The original error is wrapped.
+func Normalize(err error) error {
+	if err == nil || IsHTTPError(err) {
+		return err
+	}
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return New(coder.StatusCode(), err)
+	}
+	return err
+}
+
+// Equal checks if two errors resolve to the same status code and message.
+// It compares the resolved code and message, not the identity of the error chains.
+func Equal(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return StatusCode(a) == StatusCode(b) && Message(a) == Message(b)
+}
+
+// Message resolves the message of the first HTTP error in the chain
+// without the status prefix of Error().
+// For other errors it returns err.Error() and for nil an empty string.
+func Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	var e *httpError
+	if errors.As(err, &e) {
+		return e.Message()
+	}
+	return err.Error()
+}
+
+// HasMessage checks if err has a message other than its status text.
+// It returns false for nil and HTTP errors without a cause.
+func HasMessage(err error) bool {
+	if err == nil {
+		return false
+	}
+	var e *httpError
+	if errors.As(err, &e) {
+		msg := e.Message()
+		return msg != "" && msg != e.statusText()
+	}
+	return err.Error() != ""
+}
+
+// New creates a new HTTP error.
+// The error unwraps to err so errors.Is and errors.As reach the cause.
 func New(code int, err error) error {
 	return &httpError{err: err, code: code}
 }
 
-// Errorf creates a new HTTP error by formating a message
+// NewStrict creates a new HTTP error validating the status code.
+// It fails if code is not in the 100-599 range.
+func NewStrict(code int, err error) (error, error) {
+	if code < http.StatusContinue || code > 599 {
+		return nil, errors.Errorf("Invalid HTTP status code %d", code)
+	}
+	return New(code, err), nil
+}
+
+// NewCaller creates a new HTTP error recording the location of the caller
+func NewCaller(code int, err error) error {
+	e := &httpError{err: err, code: code}
+	_, e.file, e.line, _ = runtime.Caller(1)
+	return e
+}
+
+// Errorf creates a new HTTP error by formating a message.
+// A cause wrapped with a trailing ": %w" is preserved for errors.Is and errors.As.
 func Errorf(code int, format string, args ...interface{}) error {
 	return &httpError{
 		err:  errors.Errorf(format, args...),
@@ -47,11 +362,27 @@ func Errorf(code int, format string, args ...interface{}) error {
 	}
 }
 
-// BadRequest creates an HTTP 500 error
+// BadRequest creates an HTTP 400 error
 func BadRequest(err error) error {
 	return New(http.StatusBadRequest, err)
 }
 
+// PaymentRequired creates an HTTP 402 error
+func PaymentRequired(err error) error {
+	return New(http.StatusPaymentRequired, err)
+}
+
+// PaymentRequiredWith creates an HTTP 402 error
+// with the plan required to access the resource as a "requiredPlan" extension
+func PaymentRequiredWith(requiredPlan string, err error) error {
+	return WithExtension(PaymentRequired(err), "requiredPlan", requiredPlan)
+}
+
+// TooManyRequests creates an HTTP 429 error
+func TooManyRequests(err error) error {
+	return New(http.StatusTooManyRequests, err)
+}
+
 // InternalServerError creates an HTTP 500 error
 func InternalServerError(err error) error {
 	return New(http.StatusInternalServerError, err)
@@ -67,24 +398,36 @@ func MethodNotAllowed(err error) error {
 	return New(http.StatusMethodNotAllowed, err)
 }
 
-// Response is a response message
-type Response struct {
-	Message    string `json:"message"`
-	Error      string `json:"error"`
-	StatusCode int    `json:"statusCode"`
+// ServiceUnavailable creates an HTTP 503 error
+func ServiceUnavailable(err error) error {
+	return New(http.StatusServiceUnavailable, err)
 }
 
-func (e *httpError) MarshalJSON() ([]byte, error) {
-	err := http.StatusText(e.code)
-	msg := err
-	if e.err != nil {
-		msg = e.err.Error()
+// UnprocessableEntity creates an HTTP 422 error
+func UnprocessableEntity(err error) error {
+	return New(http.StatusUnprocessableEntity, err)
+}
+
+// PreconditionFailed creates an HTTP 412 error
+func PreconditionFailed(err error) error {
+	return New(http.StatusPreconditionFailed, err)
+}
+
+// KnownConstructors returns the status error constructors by name, ie "NotFound".
+// It is meant for tooling and for tests checking that each constructor produces its status code.
+// A new map is returned on every call.
+func KnownConstructors() map[string]func(err error) error {
+	return map[string]func(err error) error{
+		"BadRequest":          BadRequest,
+		"PaymentRequired":     PaymentRequired,
+		"NotFound":            NotFound,
+		"MethodNotAllowed":    MethodNotAllowed,
+		"PreconditionFailed":  PreconditionFailed,
+		"UnprocessableEntity": UnprocessableEntity,
+		"TooManyRequests":     TooManyRequests,
+		"InternalServerError": InternalServerError,
+		"ServiceUnavailable":  ServiceUnavailable,
 	}
-	return json.Marshal(Response{
-		Message:    msg,
-		Error:      err,
-		StatusCode: e.code,
-	})
 }
 
 // IsInformational checks if code is HTTP informational code
@@ -117,43 +460,14 @@ func IsError(code int) bool {
 	return http.StatusBadRequest <= code && code < 600
 }
 
-// FromResponse creates a new HTTP error from a response
-func FromResponse(r *http.Response) error {
-	defer r.Body.Close()
-	data, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return New(r.StatusCode, errors.Errorf("Failed to read response body: %q", err))
-	}
-	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
-	switch mediatype {
-	case "text/plain", "text/html", "text/xml":
-		return New(r.StatusCode, errors.New(string(data)))
-	case "application/json":
-		fallthrough
-	default:
-		var tmp Response
-		if err := json.Unmarshal(data, &tmp); err != nil {
-			return New(r.StatusCode, errors.Errorf("Error parsing response: %s", err))
-		}
-		return New(r.StatusCode, errors.New(tmp.Message))
-	}
-}
-
-// RespondJSON sends a JSON encoded HTTP response
-func RespondJSON(w http.ResponseWriter, x interface{}) error {
-	w.Header().Set("Content-Type", "application/json")
-	enc := json.NewEncoder(w)
-	if err, ok := x.(error); ok {
-		code := http.StatusInternalServerError
-		if coder, ok := err.(StatusCoder); ok {
-			code = coder.StatusCode()
-		}
-		w.WriteHeader(code)
-		if u, ok := err.(json.Unmarshaler); ok {
-			return enc.Encode(u)
-		}
-		return enc.Encode(New(code, err))
+// BodyAllowed checks if a response with code can have a body.
+// It returns false for 1xx, 204 and 304 codes.
+func BodyAllowed(code int) bool {
+	switch {
+	case IsInformational(code):
+		return false
+	case code == http.StatusNoContent, code == http.StatusNotModified:
+		return false
 	}
-	w.WriteHeader(http.StatusOK)
-	return enc.Encode(x)
+	return true
 }
@@ -0,0 +1,406 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestNewCallerLocation(t *testing.T) {
+	_, wantFile, wantLine, _ := runtime.Caller(0)
+	err := NewCaller(http.StatusNotFound, nil)
+	wantLine++
+	file, line := err.(*httpError).Location()
+	if filepath.Base(file) != filepath.Base(wantFile) || line != wantLine {
+		t.Errorf("Invalid location %s:%d, want %s:%d", file, line, wantFile, wantLine)
+	}
+	if file, line := New(http.StatusNotFound, nil).(*httpError).Location(); file != "" || line != 0 {
+		t.Errorf("Invalid location %s:%d for New, want none", file, line)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := New(http.StatusNotFound, nil)
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{"%v", "404 Not Found"},
+		{"%s", "404 Not Found"},
+		{"%q", `"404 Not Found"`},
+		{"%d", "%!d(string=404 Not Found)"},
+		{"%.3s", "404"},
+		{"%15s", "  404 Not Found"},
+		{"%-15v|", "404 Not Found  |"},
+	} {
+		if got := fmt.Sprintf(tc.format, err); got != tc.want {
+			t.Errorf("Invalid %s format %q, want %q", tc.format, got, tc.want)
+		}
+	}
+	err = NewCaller(http.StatusNotFound, nil)
+	file, line := err.(*httpError).Location()
+	if got, want := fmt.Sprintf("%+v", err), fmt.Sprintf("404 Not Found\n    %s:%d", file, line); got != want {
+		t.Errorf("Invalid %%+v format %q, want %q", got, want)
+	}
+}
+
+func TestStatusCodeNil(t *testing.T) {
+	if code := StatusCode(nil); code != 0 {
+		t.Errorf("Invalid status code %d for nil, want 0", code)
+	}
+	if code := StatusCode(errors.New("Plain error")); code != http.StatusInternalServerError {
+		t.Errorf("Invalid status code %d for plain error, want %d", code, http.StatusInternalServerError)
+	}
+}
+
+func TestBodyAllowed(t *testing.T) {
+	for _, code := range []int{100, 101, 103, 199, 204, 304} {
+		if BodyAllowed(code) {
+			t.Errorf("BodyAllowed(%d) is true, want false", code)
+		}
+	}
+	for _, code := range []int{200, 201, 205, 301, 400, 404, 500} {
+		if !BodyAllowed(code) {
+			t.Errorf("BodyAllowed(%d) is false, want true", code)
+		}
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	for _, code := range []int{-1, 0, 99, 600} {
+		if err, invalid := NewStrict(code, nil); invalid == nil || err != nil {
+			t.Errorf("NewStrict(%d) = %v, %v, want a construction error", code, err, invalid)
+		}
+	}
+	err, invalid := NewStrict(http.StatusTeapot, nil)
+	if invalid != nil {
+		t.Fatal(invalid)
+	}
+	if code := StatusCode(err); code != http.StatusTeapot {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusTeapot)
+	}
+}
+
+func TestStatusAliases(t *testing.T) {
+	err := NotFound(nil)
+	for name, status := range map[string]func() int{
+		"StatusCode":     err.(StatusCoder).StatusCode,
+		"HTTPStatus":     err.(interface{ HTTPStatus() int }).HTTPStatus,
+		"ResponseStatus": err.(interface{ ResponseStatus() int }).ResponseStatus,
+	} {
+		if code := status(); code != http.StatusNotFound {
+			t.Errorf("Invalid %s %d, want %d", name, code, http.StatusNotFound)
+		}
+	}
+}
+
+// sentinelError is a custom error type for errors.As
+type sentinelError struct {
+	msg string
+}
+
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+func TestUnwrapSentinel(t *testing.T) {
+	sentinel := &sentinelError{msg: "Sentinel"}
+	errs := map[string]error{
+		"New":    New(http.StatusConflict, sentinel),
+		"Errorf": Errorf(http.StatusConflict, "Saving user: %w", sentinel),
+	}
+	for name, constructor := range KnownConstructors() {
+		errs[name] = constructor(sentinel)
+	}
+	for name, err := range errs {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("%s: errors.Is does not reach the sentinel", name)
+		}
+		var target *sentinelError
+		if !errors.As(err, &target) || target != sentinel {
+			t.Errorf("%s: errors.As does not reach the sentinel", name)
+		}
+	}
+}
+
+func TestCloneConcurrent(t *testing.T) {
+	sentinel := WithCookie(WithField(WithExtension(WithHeader(NotFound(nil),
+		"X-Sentinel", "yes"),
+		"ext", "sentinel"),
+		"field", "sentinel"),
+		&http.Cookie{Name: "session", Value: "sentinel"})
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value := strconv.Itoa(i)
+			e := Clone(sentinel).(*httpError)
+			e.header.Set("X-Sentinel", value)
+			e.fields["field"] = value
+			e.extensions["ext"] = value
+			e.cookies[0].Value = value
+			err := WithHeader(e, "X-Request", value)
+			if got := CollectHeaders(err).Get("X-Sentinel"); got != value {
+				t.Errorf("Invalid header %q, want %q", got, value)
+			}
+		}(i)
+	}
+	wg.Wait()
+	e := sentinel.(*httpError)
+	if got := e.header.Get("X-Sentinel"); got != "yes" {
+		t.Errorf("Sentinel header was modified: %q", got)
+	}
+	if got := e.header.Get("X-Request"); got != "" {
+		t.Errorf("Sentinel header was added: %q", got)
+	}
+	if got := e.fields["field"]; got != "sentinel" {
+		t.Errorf("Sentinel field was modified: %v", got)
+	}
+	if got := e.extensions["ext"]; got != "sentinel" {
+		t.Errorf("Sentinel extension was modified: %v", got)
+	}
+	if got := e.cookies[0].Value; got != "sentinel" {
+		t.Errorf("Sentinel cookie was modified: %q", got)
+	}
+}
+
+func TestCloneForeign(t *testing.T) {
+	err := errors.New("Plain error")
+	if Clone(err) != err {
+		t.Error("Clone did not return a foreign error as is")
+	}
+	if Clone(nil) != nil {
+		t.Error("Clone did not return nil as is")
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	err := InternalServerError(NotFound(errors.New("No such user")))
+	got := StatusCodes(err)
+	if len(got) != 2 || got[0] != http.StatusInternalServerError || got[1] != http.StatusNotFound {
+		t.Errorf("Invalid status codes %v, want [500 404]", got)
+	}
+	if got := StatusCodes(errors.New("Plain error")); len(got) != 0 {
+		t.Errorf("Invalid status codes %v for a plain error, want none", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, tc := range []struct {
+		a, b error
+		want bool
+	}{
+		{NotFound(errors.New("No such user")), NotFound(errors.New("No such user")), true},
+		{NotFound(nil), New(http.StatusNotFound, nil), true},
+		{nil, nil, true},
+		{NotFound(errors.New("No such user")), NotFound(errors.New("No such team")), false},
+		{NotFound(nil), BadRequest(nil), false},
+		{NotFound(nil), nil, false},
+		{nil, NotFound(nil), false},
+	} {
+		if got := Equal(tc.a, tc.b); got != tc.want {
+			t.Errorf("Equal(%v, %v) = %t, want %t", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{NotFound(errors.New("No such user")), "No such user"},
+		{NotFound(nil), "Not Found"},
+		{InternalServerError(NotFound(errors.New("No such user"))), "No such user"},
+		{errors.New("Plain error"), "Plain error"},
+		{nil, ""},
+	} {
+		if got := Message(tc.err); got != tc.want {
+			t.Errorf("Invalid message %q for %v, want %q", got, tc.err, tc.want)
+		}
+	}
+}
+
+func TestPaymentRequiredWith(t *testing.T) {
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, PaymentRequiredWith("pro", errors.New("Upgrade to export")))
+	if rr.Code != http.StatusPaymentRequired {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusPaymentRequired)
+	}
+	var body struct {
+		Message      string `json:"message"`
+		RequiredPlan string `json:"requiredPlan"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.RequiredPlan != "pro" || body.Message != "Upgrade to export" {
+		t.Errorf("Invalid body %s", rr.Body.Bytes())
+	}
+}
+
+// foreignError is a StatusCoder from another library
+type foreignError struct {
+	code int
+}
+
+func (e *foreignError) Error() string {
+	return http.StatusText(e.code)
+}
+
+func (e *foreignError) StatusCode() int {
+	return e.code
+}
+
+func TestIsHTTPError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"own", NotFound(nil), true},
+		{"wrapped", errors.Errorf("Loading user: %w", NotFound(nil)), true},
+		{"foreign", &foreignError{code: http.StatusNotFound}, false},
+		{"plain", errors.New("Plain error"), false},
+		{"nil", nil, false},
+	} {
+		if got := IsHTTPError(tc.err); got != tc.want {
+			t.Errorf("%s: IsHTTPError(%v) = %t, want %t", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	foreign := &foreignError{code: http.StatusTeapot}
+	err := Normalize(foreign)
+	if !IsHTTPError(err) || StatusCode(err) != http.StatusTeapot {
+		t.Errorf("Invalid normalized error %v", err)
+	}
+	if !errors.Is(err, foreign) {
+		t.Errorf("Normalized error %v does not wrap %v", err, foreign)
+	}
+	own := NotFound(nil)
+	plain := errors.New("Plain error")
+	for _, err := range []error{own, plain, nil} {
+		if got := Normalize(err); got != err {
+			t.Errorf("Normalize(%v) = %v, want it as is", err, got)
+		}
+	}
+}
+
+func TestMessageNested(t *testing.T) {
+	err := InternalServerError(New(http.StatusBadGateway, NotFound(errors.New("No such user"))))
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, err)
+	var resp Response
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Message != "No such user" {
+		t.Errorf("Invalid message %q, want %q", resp.Message, "No such user")
+	}
+}
+
+func TestHasMessage(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{NotFound(nil), false},
+		{NotFound(errors.New("No such user")), true},
+		{errors.New("Plain error"), true},
+	} {
+		if got := HasMessage(tc.err); got != tc.want {
+			t.Errorf("Invalid HasMessage(%v) %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestKnownConstructors(t *testing.T) {
+	want := map[string]int{
+		"BadRequest":          http.StatusBadRequest,
+		"PaymentRequired":     http.StatusPaymentRequired,
+		"NotFound":            http.StatusNotFound,
+		"MethodNotAllowed":    http.StatusMethodNotAllowed,
+		"PreconditionFailed":  http.StatusPreconditionFailed,
+		"UnprocessableEntity": http.StatusUnprocessableEntity,
+		"TooManyRequests":     http.StatusTooManyRequests,
+		"InternalServerError": http.StatusInternalServerError,
+		"ServiceUnavailable":  http.StatusServiceUnavailable,
+	}
+	constructors := KnownConstructors()
+	if len(constructors) != len(want) {
+		t.Errorf("Invalid number of constructors %d, want %d", len(constructors), len(want))
+	}
+	for name, code := range want {
+		newError, ok := constructors[name]
+		if !ok {
+			t.Errorf("Missing constructor %s", name)
+			continue
+		}
+		if got := StatusCode(newError(nil)); got != code {
+			t.Errorf("Invalid status code %d for %s, want %d", got, name, code)
+		}
+	}
+}
+
+func TestReplaceMessage(t *testing.T) {
+	cause := errors.New("Query failed: password=secret")
+	err := WithRetryAfter(WithField(WithHeader(ServiceUnavailable(cause), "X-Request-Id", "abc"), "userId", 42), time.Minute)
+	e := err.(*httpError)
+	replaced := e.ReplaceMessage("Database unavailable")
+	if got := replaced.Message(); got != "Database unavailable" {
+		t.Errorf("Invalid message %q", got)
+	}
+	if errors.Is(replaced, cause) {
+		t.Error("Replaced error still wraps the cause")
+	}
+	if got := StatusCode(replaced); got != http.StatusServiceUnavailable {
+		t.Errorf("Invalid status code %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if got := Fields(replaced)["userId"]; got != 42 {
+		t.Errorf("Invalid field %v, want 42", got)
+	}
+	if got := RetryAfter(replaced); got != time.Minute {
+		t.Errorf("Invalid Retry-After %s, want %s", got, time.Minute)
+	}
+	rr := httptest.NewRecorder()
+	SetErrorHeaders(rr, replaced)
+	if got := rr.Header().Get("X-Request-Id"); got != "abc" {
+		t.Errorf("Invalid header %q, want %q", got, "abc")
+	}
+	if got := e.Message(); got != cause.Error() {
+		t.Errorf("Original error was modified: %q", got)
+	}
+}
+
+func TestBuildersNil(t *testing.T) {
+	for name, build := range map[string]func(err error) error{
+		"WithHeader":     func(err error) error { return WithHeader(err, "X-Request-Id", "abc") },
+		"WithField":      func(err error) error { return WithField(err, "id", 42) },
+		"WithExtension":  func(err error) error { return WithExtension(err, "balance", 30) },
+		"WithCookie":     func(err error) error { return WithCookie(err, &http.Cookie{Name: "session"}) },
+		"WithRetryAfter": func(err error) error { return WithRetryAfter(err, time.Minute) },
+		"WithSunset":     func(err error) error { return WithSunset(err, time.Now()) },
+		"WithWarning":    func(err error) error { return WithWarning(err, 110, "Response is Stale") },
+		"WithType":       func(err error) error { return WithType(err, "https://example.com/errors/not-found") },
+		"AsPublic":       AsPublic,
+		"AsInternal":     AsInternal,
+	} {
+		if err := build(nil); err != nil {
+			t.Errorf("Invalid %s error %v for nil, want nil", name, err)
+		}
+		if got := StatusCode(build(NotFound(nil))); got != http.StatusNotFound {
+			t.Errorf("Invalid %s status code %d, want %d", name, got, http.StatusNotFound)
+		}
+	}
+}
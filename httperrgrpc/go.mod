@@ -0,0 +1,13 @@
+module github.com/alxarch/httperr/httperrgrpc
+
+go 1.26.0
+
+require (
+	github.com/alxarch/httperr v0.0.0-20261014143250-fa2d9919eabe
+	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260921155816-b14227669459
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require golang.org/x/sys v0.47.0 // indirect
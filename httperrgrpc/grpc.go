@@ -0,0 +1,103 @@
+// Package httperrgrpc converts httperr errors to gRPC statuses.
+//
+// It is a separate module so that httperr does not depend on gRPC.
+package httperrgrpc
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/alxarch/httperr"
+	errors "golang.org/x/xerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// GRPCCode maps the status code of err to a gRPC code.
+// Status codes without a gRPC equivalent map to codes.Unknown.
+func GRPCCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	code := httperr.StatusCode(err)
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case httperr.StatusClientClosedRequest:
+		return codes.Canceled
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httperr.IsSuccess(code) {
+		return codes.OK
+	}
+	return codes.Unknown
+}
+
+// ToGRPCStatus creates a gRPC status with the mapped code and message of err.
+// Validation errors are attached as BadRequest and Retry-After delays as RetryInfo details.
+// A nil error results in an OK status.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	s := status.New(GRPCCode(err), httperr.Message(err))
+	var details []protoadapt.MessageV1
+	var v httperr.ValidationError
+	if errors.As(err, &v) {
+		details = append(details, badRequest(v))
+	}
+	if d := httperr.RetryAfter(err); d > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+	if len(details) == 0 {
+		return s
+	}
+	withDetails, detailsErr := s.WithDetails(details...)
+	if detailsErr != nil {
+		return s
+	}
+	return withDetails
+}
+
+// badRequest converts a validation error to field violations sorted by field
+func badRequest(v httperr.ValidationError) *errdetails.BadRequest {
+	fields := make([]string, 0, len(v))
+	for field := range v {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	br := &errdetails.BadRequest{}
+	for _, field := range fields {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: v[field],
+		})
+	}
+	return br
+}
@@ -0,0 +1,68 @@
+package httperrgrpc
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alxarch/httperr"
+	errors "golang.org/x/xerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGRPCCode(t *testing.T) {
+	for code, want := range map[int]codes.Code{
+		http.StatusOK:                     codes.OK,
+		http.StatusBadRequest:             codes.InvalidArgument,
+		http.StatusUnauthorized:           codes.Unauthenticated,
+		http.StatusForbidden:              codes.PermissionDenied,
+		http.StatusNotFound:               codes.NotFound,
+		http.StatusRequestTimeout:         codes.DeadlineExceeded,
+		http.StatusConflict:               codes.Aborted,
+		http.StatusTooManyRequests:        codes.ResourceExhausted,
+		httperr.StatusClientClosedRequest: codes.Canceled,
+		http.StatusInternalServerError:    codes.Internal,
+		http.StatusNotImplemented:         codes.Unimplemented,
+		http.StatusBadGateway:             codes.Unavailable,
+		http.StatusServiceUnavailable:     codes.Unavailable,
+		http.StatusGatewayTimeout:         codes.DeadlineExceeded,
+		http.StatusTeapot:                 codes.Unknown,
+	} {
+		if got := GRPCCode(httperr.New(code, nil)); got != want {
+			t.Errorf("GRPCCode(%d) = %s, want %s", code, got, want)
+		}
+	}
+	if got := GRPCCode(nil); got != codes.OK {
+		t.Errorf("GRPCCode(nil) = %s, want %s", got, codes.OK)
+	}
+}
+
+func TestToGRPCStatus(t *testing.T) {
+	s := ToGRPCStatus(httperr.NotFound(errors.New("No such user")))
+	if s.Code() != codes.NotFound || s.Message() != "No such user" {
+		t.Errorf("Invalid status %s %q", s.Code(), s.Message())
+	}
+
+	err := httperr.WithRetryAfter(httperr.ValidationError{"name": "Required", "age": "Too low"}, 2*time.Second)
+	s = ToGRPCStatus(err)
+	if s.Code() != codes.InvalidArgument {
+		t.Errorf("Invalid code %s, want %s", s.Code(), codes.InvalidArgument)
+	}
+	details := s.Details()
+	if len(details) != 2 {
+		t.Fatalf("Invalid details %v", details)
+	}
+	br, ok := details[0].(*errdetails.BadRequest)
+	if !ok || len(br.FieldViolations) != 2 || br.FieldViolations[0].Field != "age" || br.FieldViolations[1].Field != "name" {
+		t.Errorf("Invalid bad request details %v", details[0])
+	}
+	ri, ok := details[1].(*errdetails.RetryInfo)
+	if !ok || ri.RetryDelay.AsDuration() != 2*time.Second {
+		t.Errorf("Invalid retry info %v", details[1])
+	}
+
+	if s := ToGRPCStatus(nil); s.Code() != codes.OK {
+		t.Errorf("Invalid code %s for nil, want %s", s.Code(), codes.OK)
+	}
+}
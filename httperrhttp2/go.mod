@@ -0,0 +1,13 @@
+module github.com/alxarch/httperr/httperrhttp2
+
+go 1.26.0
+
+require (
+	github.com/alxarch/httperr v0.0.0-20261014143250-fa2d9919eabe
+	golang.org/x/net v0.59.0
+)
+
+require (
+	golang.org/x/text v0.42.0 // indirect
+	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7 // indirect
+)
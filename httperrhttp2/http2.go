@@ -0,0 +1,45 @@
+// Package httperrhttp2 resets HTTP/2 streams with error codes.
+//
+// It is a separate module so that httperr does not depend on golang.org/x/net/http2.
+package httperrhttp2
+
+import (
+	"net/http"
+
+	"github.com/alxarch/httperr"
+	"golang.org/x/net/http2"
+)
+
+// StreamResetter is a response writer that can reset its HTTP/2 stream.
+//
+// Neither net/http nor golang.org/x/net/http2 response writers implement it,
+// servers or proxies that can reset streams must provide their own writer.
+type StreamResetter interface {
+	ResetStream(code http2.ErrCode) error
+}
+
+// ResetStream resets the HTTP/2 stream of w with code.
+// Writers wrapping a StreamResetter, like the httperr middleware, are unwrapped with their
+// `Unwrap() http.ResponseWriter` method.
+// If no writer in the chain implements StreamResetter it responds with an HTTP 500 error instead.
+func ResetStream(w http.ResponseWriter, code http2.ErrCode) error {
+	if rs, ok := streamResetter(w); ok {
+		return rs.ResetStream(code)
+	}
+	return httperr.RespondJSON(w, httperr.Errorf(http.StatusInternalServerError, "Stream error: %s", code))
+}
+
+// streamResetter finds a StreamResetter unwrapping response writers
+func streamResetter(w http.ResponseWriter) (StreamResetter, bool) {
+	for w != nil {
+		if rs, ok := w.(StreamResetter); ok {
+			return rs, true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil, false
+		}
+		w = u.Unwrap()
+	}
+	return nil, false
+}
@@ -0,0 +1,50 @@
+package httperrhttp2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxarch/httperr"
+	"golang.org/x/net/http2"
+)
+
+// resetRecorder is a StreamResetter recording the reset code
+type resetRecorder struct {
+	*httptest.ResponseRecorder
+	code  http2.ErrCode
+	reset bool
+}
+
+func (w *resetRecorder) ResetStream(code http2.ErrCode) error {
+	w.code = code
+	w.reset = true
+	return nil
+}
+
+func TestResetStreamFallback(t *testing.T) {
+	rr := httptest.NewRecorder()
+	if err := ResetStream(rr, http2.ErrCodeCancel); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Invalid content type %q, want %q", got, "application/json")
+	}
+}
+
+func TestResetStreamUnwrap(t *testing.T) {
+	w := &resetRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h := httperr.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return ResetStream(w, http2.ErrCodeRefusedStream)
+	})
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !w.reset || w.code != http2.ErrCodeRefusedStream {
+		t.Errorf("Stream was not reset through the middleware writer: %t %s", w.reset, w.code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Invalid body %q after reset", w.Body.String())
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/alxarch/httperr/httperrotel
+
+go 1.26.0
+
+require (
+	github.com/alxarch/httperr v0.0.0-20261014143250-fa2d9919eabe
+	go.opentelemetry.io/otel/trace v1.46.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	go.opentelemetry.io/otel v1.46.0 // indirect
+	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7 // indirect
+)
@@ -0,0 +1,20 @@
+// Package httperrotel adds OpenTelemetry trace IDs to httperr error responses.
+//
+// It is a separate module so that httperr does not depend on OpenTelemetry.
+package httperrotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceID returns the trace ID of the span in ctx or an empty string if there is none.
+// It can be used as the TraceID option of an httperr.Responder.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
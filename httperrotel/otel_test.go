@@ -0,0 +1,44 @@
+package httperrotel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxarch/httperr"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceID(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	want := traceID.String()
+	if got := TraceID(ctx); got != want {
+		t.Errorf("Invalid trace ID %q, want %q", got, want)
+	}
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("Invalid trace ID %q without a span, want none", got)
+	}
+
+	rs := &httperr.Responder{TraceID: TraceID}
+	rr := httptest.NewRecorder()
+	rs.RespondJSONContext(ctx, rr, httperr.NotFound(nil))
+	var body struct {
+		TraceID string `json:"traceId"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.TraceID != want {
+		t.Errorf("Invalid traceId %q, want %q", body.TraceID, want)
+	}
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
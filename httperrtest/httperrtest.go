@@ -0,0 +1,34 @@
+// Package httperrtest provides utilities for testing handlers that respond with httperr errors.
+package httperrtest
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxarch/httperr"
+)
+
+// AssertErrorBody checks that a recorded response is a JSON error
+// with the expected status code and message.
+func AssertErrorBody(t testing.TB, rr *httptest.ResponseRecorder, wantCode int, wantMessage string) {
+	t.Helper()
+	if rr.Code != wantCode {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, wantCode)
+	}
+	contentType := rr.Header().Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+		t.Errorf("Invalid content type %q, want %q", contentType, "application/json")
+	}
+	var resp httperr.Response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode error body %q: %s", rr.Body.Bytes(), err)
+	}
+	if resp.StatusCode != wantCode {
+		t.Errorf("Invalid body status code %d, want %d", resp.StatusCode, wantCode)
+	}
+	if resp.Message != wantMessage {
+		t.Errorf("Invalid message %q, want %q", resp.Message, wantMessage)
+	}
+}
@@ -0,0 +1,19 @@
+package httperrtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxarch/httperr"
+	errors "golang.org/x/xerrors"
+)
+
+func TestAssertErrorBody(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httperr.RespondJSON(w, httperr.NotFound(errors.New("No such user")))
+	})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	AssertErrorBody(t, rr, http.StatusNotFound, "No such user")
+}
@@ -0,0 +1,19 @@
+module github.com/alxarch/httperr/httperrvalidator
+
+go 1.26.0
+
+require (
+	github.com/alxarch/httperr v0.0.0-20261014143250-fa2d9919eabe
+	github.com/go-playground/validator/v10 v10.30.5
+	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
+)
+
+require (
+	github.com/gabriel-vasile/mimetype v1.4.15 // indirect
+	github.com/go-playground/locales v0.14.1 // indirect
+	github.com/go-playground/universal-translator v0.18.1 // indirect
+	github.com/leodido/go-urn v1.5.0 // indirect
+	golang.org/x/crypto v0.57.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/text v0.42.0 // indirect
+)
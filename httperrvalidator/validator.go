@@ -0,0 +1,29 @@
+// Package httperrvalidator converts go-playground/validator errors to httperr validation errors.
+//
+// It is a separate module so that httperr does not depend on the validator package.
+package httperrvalidator
+
+import (
+	"github.com/alxarch/httperr"
+	"github.com/go-playground/validator/v10"
+	errors "golang.org/x/xerrors"
+)
+
+// FromValidator converts validator.ValidationErrors to an httperr.ValidationError.
+// Each field's message is the failed tag and its parameter, ie "required" or "min=3".
+// Other errors are returned as is.
+func FromValidator(err error) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	v := make(httperr.ValidationError, len(errs))
+	for _, fe := range errs {
+		msg := fe.Tag()
+		if param := fe.Param(); param != "" {
+			msg += "=" + param
+		}
+		v[fe.Field()] = msg
+	}
+	return v
+}
@@ -0,0 +1,47 @@
+package httperrvalidator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alxarch/httperr"
+	"github.com/go-playground/validator/v10"
+	errors "golang.org/x/xerrors"
+)
+
+type signup struct {
+	Name  string `validate:"required"`
+	Email string `validate:"required,email"`
+	Age   int    `validate:"min=18"`
+}
+
+func TestFromValidator(t *testing.T) {
+	verr := validator.New().Struct(signup{Email: "nope", Age: 3})
+	err := FromValidator(verr)
+	if code := httperr.StatusCode(err); code != http.StatusUnprocessableEntity {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	var v httperr.ValidationError
+	if !errors.As(err, &v) {
+		t.Fatalf("Invalid error %T, want httperr.ValidationError", err)
+	}
+	want := map[string]string{"Name": "required", "Email": "email", "Age": "min=18"}
+	if len(v) != len(want) {
+		t.Errorf("Invalid validation messages %v, want %v", v, want)
+	}
+	for field, msg := range want {
+		if v[field] != msg {
+			t.Errorf("Invalid message %q for %s, want %q", v[field], field, msg)
+		}
+	}
+}
+
+func TestFromValidatorOther(t *testing.T) {
+	other := errors.New("Not a validation error")
+	if err := FromValidator(other); err != other {
+		t.Errorf("Invalid error %v, want it returned as is", err)
+	}
+	if err := FromValidator(nil); err != nil {
+		t.Errorf("Invalid error %v for nil, want nil", err)
+	}
+}
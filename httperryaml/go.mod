@@ -0,0 +1,9 @@
+module github.com/alxarch/httperr/httperryaml
+
+go 1.26.0
+
+require (
+	github.com/alxarch/httperr v0.0.0-20261014143250-fa2d9919eabe
+	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
+	gopkg.in/yaml.v3 v3.0.1
+)
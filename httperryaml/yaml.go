@@ -0,0 +1,69 @@
+// Package httperryaml sends httperr responses encoded as YAML.
+//
+// It is a separate module so that httperr does not depend on a YAML package.
+package httperryaml
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/alxarch/httperr"
+	"gopkg.in/yaml.v3"
+)
+
+// RespondYAML sends a YAML encoded HTTP response.
+// If x is an error the status code is resolved with httperr.StatusCode
+// and the body is the error's httperr.Response, otherwise x is sent with status 200.
+func RespondYAML(w http.ResponseWriter, x interface{}) error {
+	code := http.StatusOK
+	var body []byte
+	var err error
+	if e, ok := x.(error); ok {
+		code = httperr.StatusCode(e)
+		httperr.SetErrorHeaders(w, e)
+		body, err = errorBody(e)
+	} else {
+		body, err = yaml.Marshal(x)
+	}
+	if err != nil {
+		return err
+	}
+	h := w.Header()
+	h.Set("Content-Type", "application/x-yaml")
+	if !httperr.BodyAllowed(code) {
+		w.WriteHeader(code)
+		return nil
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(code)
+	_, err = w.Write(body)
+	return err
+}
+
+// errorBody encodes an error body with the same keys as its JSON body.
+// The JSON body is decoded as a YAML document so that keys keep their order.
+func errorBody(err error) ([]byte, error) {
+	var v interface{} = httperr.NewResponse(err)
+	if m, ok := err.(json.Marshaler); ok && !httperr.IsHTTPError(err) {
+		v = m
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+	return yaml.Marshal(&doc)
+}
+
+// blockStyle clears the JSON flow and quoting styles of a node and its children
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,39 @@
+package httperryaml
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxarch/httperr"
+	errors "golang.org/x/xerrors"
+)
+
+func TestRespondYAML(t *testing.T) {
+	rr := httptest.NewRecorder()
+	err := httperr.WithExtension(httperr.NotFound(errors.New("No such user")), "code", "E42")
+	if err := RespondYAML(rr, err); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/x-yaml" {
+		t.Errorf("Invalid content type %q, want %q", got, "application/x-yaml")
+	}
+	want := "message: No such user\nerror: Not Found\nstatusCode: 404\ncode: E42\n"
+	if got := rr.Body.String(); got != want {
+		t.Errorf("Invalid body %q, want %q", got, want)
+	}
+}
+
+func TestRespondYAMLValue(t *testing.T) {
+	rr := httptest.NewRecorder()
+	RespondYAML(rr, map[string]int{"id": 42})
+	if rr.Code != http.StatusOK {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusOK)
+	}
+	if got := rr.Body.String(); got != "id: 42\n" {
+		t.Errorf("Invalid body %q, want %q", got, "id: 42\n")
+	}
+}
@@ -0,0 +1,32 @@
+package httperr
+
+import (
+	"sync"
+	"time"
+)
+
+// lastError holds the most recent error a Responder rendered
+type lastError struct {
+	mu  sync.Mutex
+	err error
+	at  time.Time
+}
+
+// LastError returns the most recent error rendered and the time it was rendered.
+// Errors are only recorded if RecordLastError is set.
+func (rs *Responder) LastError() (error, time.Time) {
+	rs.last.mu.Lock()
+	defer rs.last.mu.Unlock()
+	return rs.last.err, rs.last.at
+}
+
+// recordError records err as the last error if RecordLastError is set
+func (rs *Responder) recordError(err error) {
+	if !rs.RecordLastError {
+		return
+	}
+	at := rs.now()
+	rs.last.mu.Lock()
+	defer rs.last.mu.Unlock()
+	rs.last.err, rs.last.at = err, at
+}
@@ -0,0 +1,48 @@
+package httperr
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLastError(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	rs := &Responder{RecordLastError: true, Now: func() time.Time { return now }}
+	if err, at := rs.LastError(); err != nil || !at.IsZero() {
+		t.Errorf("Invalid last error %v at %s before any response", err, at)
+	}
+	first, second := NotFound(nil), BadRequest(nil)
+	rs.RespondJSON(httptest.NewRecorder(), first)
+	rs.RespondJSON(httptest.NewRecorder(), second)
+	rs.RespondJSON(httptest.NewRecorder(), "not an error")
+	if err, at := rs.LastError(); err != second || !at.Equal(now) {
+		t.Errorf("Invalid last error %v at %s, want %v at %s", err, at, second, now)
+	}
+
+	disabled := &Responder{}
+	disabled.RespondJSON(httptest.NewRecorder(), first)
+	if err, _ := disabled.LastError(); err != nil {
+		t.Errorf("Invalid last error %v when recording is disabled", err)
+	}
+}
+
+func TestLastErrorConcurrent(t *testing.T) {
+	rs := &Responder{RecordLastError: true}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				rs.RespondJSON(httptest.NewRecorder(), NotFound(nil))
+				rs.LastError()
+			}
+		}()
+	}
+	wg.Wait()
+	if err, _ := rs.LastError(); err == nil {
+		t.Error("No last error recorded")
+	}
+}
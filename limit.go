@@ -0,0 +1,112 @@
+package httperr
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	errors "golang.org/x/xerrors"
+)
+
+// LimitBody is a middleware that limits request bodies to max bytes with http.MaxBytesReader.
+// If the handler hits the limit, its response is replaced with an HTTP 413 error sent with RespondJSON.
+func LimitBody(next http.Handler, max int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, max)}
+		r.Body = body
+		lw := &limitWriter{ResponseWriter: w, body: body}
+		next.ServeHTTP(exposeWriter(lw), r)
+		if body.exceeded && !lw.wroteHeader {
+			lw.tooLarge()
+		}
+	})
+}
+
+// limitedBody records if reading a request body failed because it exceeded its limit
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var tooLarge *http.MaxBytesError
+	if err != nil && errors.As(err, &tooLarge) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// limitWriter replaces the response with an HTTP 413 error once the request body exceeded its limit
+type limitWriter struct {
+	http.ResponseWriter
+	body        *limitedBody
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *limitWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	if w.body.exceeded {
+		w.tooLarge()
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.discard {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController
+func (w *limitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *limitWriter) tooLarge() {
+	w.wroteHeader = true
+	w.discard = true
+	RespondJSON(w.ResponseWriter, New(http.StatusRequestEntityTooLarge, errors.New("Request body too large")))
+}
+
+// Flush sends buffered data to the client unless the response was replaced
+func (w *limitWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	if !w.discard {
+		w.ResponseWriter.(http.Flusher).Flush()
+	}
+}
+
+// Hijack takes over the connection, the response can no longer be replaced afterwards
+func (w *limitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+func (w *limitWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *limitWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.discard {
+		return io.Copy(ioutil.Discard, r)
+	}
+	return w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+}
@@ -0,0 +1,90 @@
+package httperr
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestLimitBody(t *testing.T) {
+	h := LimitBody(Handler(func(w http.ResponseWriter, r *http.Request) error {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return BadRequest(err)
+		}
+		_, err = w.Write(data)
+		return err
+	}), 8)
+	for _, tc := range []struct {
+		name string
+		body string
+		code int
+	}{
+		{"small", "12345678", http.StatusOK},
+		{"oversized", "123456789", http.StatusRequestEntityTooLarge},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+			if rr.Code != tc.code {
+				t.Errorf("Invalid status code %d, want %d", rr.Code, tc.code)
+			}
+		})
+	}
+}
+
+// failingReader fails after returning its data
+type failingReader struct {
+	data string
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, errors.New("Connection reset")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestLimitBodyReadError(t *testing.T) {
+	h := LimitBody(Handler(func(w http.ResponseWriter, r *http.Request) error {
+		if _, err := io.Copy(ioutil.Discard, r.Body); err != nil {
+			return BadRequest(err)
+		}
+		return nil
+	}), 8)
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Body = ioutil.NopCloser(&failingReader{data: "12345678"})
+	h.ServeHTTP(rr, r)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLimitBodyFlusher(t *testing.T) {
+	var flushed bool
+	h := LimitBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("Writer does not implement http.Flusher")
+		}
+		if _, ok := w.(http.Hijacker); ok {
+			t.Error("Writer implements http.Hijacker")
+		}
+		io.WriteString(w, "partial")
+		f.Flush()
+		flushed = true
+	}), 8)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body")))
+	if !flushed || !rr.Flushed {
+		t.Error("Response was not flushed")
+	}
+}
@@ -0,0 +1,31 @@
+package httperr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// logEntry is a JSON log line written by LogJSON
+type logEntry struct {
+	Status  int    `json:"status"`
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// LogJSON writes err to w as a single line JSON object with status, method, path and message.
+// It is meant for logging, not for responses.
+func LogJSON(w io.Writer, r *http.Request, err error) error {
+	entry := logEntry{
+		Status:  StatusCode(err),
+		Message: Message(err),
+	}
+	if r != nil {
+		entry.Method = r.Method
+		if r.URL != nil {
+			entry.Path = r.URL.Path
+		}
+	}
+	return json.NewEncoder(w).Encode(entry)
+}
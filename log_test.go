@@ -0,0 +1,38 @@
+package httperr
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestLogJSON(t *testing.T) {
+	err := NotFound(errors.New("No such user"))
+	for _, tc := range []struct {
+		name string
+		r    *http.Request
+		want string
+	}{
+		{"request", httptest.NewRequest(http.MethodGet, "/users/42?q=1", nil),
+			`{"status":404,"method":"GET","path":"/users/42","message":"No such user"}` + "\n"},
+		{"nil request", nil, `{"status":404,"message":"No such user"}` + "\n"},
+		{"nil URL", &http.Request{Method: http.MethodPost},
+			`{"status":404,"method":"POST","message":"No such user"}` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := LogJSON(&buf, tc.r, err); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("Invalid log line %q, want %q", got, tc.want)
+			}
+			if bytes.Count(buf.Bytes(), []byte{'\n'}) != 1 {
+				t.Errorf("Log entry %q is not a single line", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package httperr
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+// HandlerFunc is an HTTP handler that can fail with an error
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler converts h to an http.Handler using the default Responder
+func Handler(h HandlerFunc) http.Handler {
+	return defaultResponder.Handler(h)
+}
+
+// Handler converts h to an http.Handler that responds with the errors h returns.
+//
+// If h already started writing the response when it fails, the status
+// can no longer change so the error is only passed to the Logger.
+func (rs *Responder) Handler(h HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+		if err := h(exposeWriter(rw), r); err != nil {
+			rs.fail(rw, r, err, start)
+		}
+	})
+}
+
+// ErrorHandlerFunc returns a handler that always responds with err using RespondJSON.
+// It is useful as the NotFound or MethodNotAllowed handler of routers.
+func ErrorHandlerFunc(err error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		RespondJSONContext(r.Context(), w, err)
+	}
+}
+
+// ServeHTTP responds with the error using Respond so that HTTP errors can be mounted as handlers
+func (e *httpError) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Respond(w, r, e)
+}
+
+// Recover is a middleware that recovers from panics using the default Responder
+func Recover(next http.Handler) http.Handler {
+	return defaultResponder.Recover(next)
+}
+
+// Recover is a middleware that recovers from panics in next and responds with an error.
+// Panics with http.ErrAbortHandler are propagated.
+// Like Handler, if the response was already started the error is only logged.
+func (rs *Responder) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				rs.fail(rw, r, rs.panicError(p), start)
+			}
+		}()
+		next.ServeHTTP(exposeWriter(rw), r)
+	})
+}
+
+// abort is the panic value of Abort
+type abort struct {
+	err error
+}
+
+// Abort stops a handler by panicking with err so that Catch responds with it.
+// A nil err is sent as an HTTP 500 error.
+func Abort(err error) {
+	if err == nil {
+		err = InternalServerError(nil)
+	}
+	panic(abort{err: err})
+}
+
+// Catch is a middleware that responds to errors passed to Abort using the default Responder
+func Catch(next http.Handler) http.Handler {
+	return defaultResponder.Catch(next)
+}
+
+// Catch is a middleware that recovers from panics caused by Abort in next and responds with their error.
+// Other panics are propagated. Like Handler, if the response was already started the error is only logged.
+func (rs *Responder) Catch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			if p := recover(); p != nil {
+				a, ok := p.(abort)
+				if !ok {
+					panic(p)
+				}
+				rs.fail(rw, r, a.err, start)
+			}
+		}()
+		next.ServeHTTP(exposeWriter(rw), r)
+	})
+}
+
+// Recovered converts a value recovered from a panic to an HTTP 500 error with the current stack.
+// Values that are not errors are formatted as the message. It is meant for custom recover blocks:
+//
+//	defer func() {
+//		if p := recover(); p != nil {
+//			httperr.RespondJSON(w, httperr.Recovered(p))
+//		}
+//	}()
+func Recovered(p interface{}) error {
+	if a, ok := p.(abort); ok {
+		return a.err
+	}
+	err, ok := p.(error)
+	if !ok {
+		err = errors.Errorf("Panic: %v", p)
+	}
+	return &httpError{
+		code:  http.StatusInternalServerError,
+		err:   err,
+		stack: debug.Stack(),
+	}
+}
+
+// Stack returns the stack of the outermost error in the chain created with Recovered
+func Stack(err error) []byte {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*httpError); ok && len(e.stack) > 0 {
+			return e.stack
+		}
+	}
+	return nil
+}
+
+func (rs *Responder) panicError(p interface{}) error {
+	if a, ok := p.(abort); ok {
+		return a.err
+	}
+	if rs.PanicMapper != nil {
+		if err := rs.PanicMapper(p); err != nil {
+			return err
+		}
+	}
+	if err, ok := p.(error); ok {
+		return InternalServerError(err)
+	}
+	return InternalServerError(errors.Errorf("Panic: %v", p))
+}
+
+// fail responds with err unless the response was already started and logs it
+func (rs *Responder) fail(w *responseWriter, r *http.Request, err error, start time.Time) {
+	if !w.wroteHeader {
+		rs.setTraceParent(w, r)
+		if !rs.dispatch(w, r, err) {
+			rs.respondDefault(r.Context(), w, err)
+		}
+	}
+	if rs.Logger != nil {
+		if rs.LogCauseType {
+			err = &causeTypeError{err: err}
+		}
+		rs.Logger(r, err, w.bytes, time.Since(start))
+	}
+}
+
+// responseWriter tracks the headers and bytes written to a response.
+// Handlers get it through exposeWriter so that only the optional interfaces
+// of the underlying writer are visible.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	bytes       int
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends buffered data to the client, the header can no longer change afterwards
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// Hijack takes over the connection, errors can no longer be sent afterwards
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+	w.bytes += int(n)
+	return n, err
+}
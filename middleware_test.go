@@ -0,0 +1,319 @@
+package httperr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+var errForbiddenPanic = errors.New("Forbidden panic")
+
+func TestRecoverPanicMapper(t *testing.T) {
+	rs := &Responder{
+		PanicMapper: func(p interface{}) error {
+			if p == errForbiddenPanic {
+				return New(http.StatusForbidden, errForbiddenPanic)
+			}
+			return nil
+		},
+	}
+	for _, tc := range []struct {
+		name  string
+		panic interface{}
+		code  int
+	}{
+		{"mapped", errForbiddenPanic, http.StatusForbidden},
+		{"unmapped", "boom", http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := rs.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic(tc.panic)
+			}))
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rr.Code != tc.code {
+				t.Errorf("Invalid status code %d, want %d", rr.Code, tc.code)
+			}
+		})
+	}
+}
+
+func TestHandlerLoggerBytes(t *testing.T) {
+	var logged int
+	rs := &Responder{
+		Logger: func(r *http.Request, err error, bytes int, dur time.Duration) {
+			logged = bytes
+		},
+	}
+	for _, tc := range []struct {
+		name string
+		h    HandlerFunc
+	}{
+		{"error", func(w http.ResponseWriter, r *http.Request) error {
+			return NotFound(errors.New("No such user"))
+		}},
+		{"partial", func(w http.ResponseWriter, r *http.Request) error {
+			io.WriteString(w, "partial body")
+			return InternalServerError(nil)
+		}},
+		{"readFrom", func(w http.ResponseWriter, r *http.Request) error {
+			io.Copy(w, strings.NewReader("copied body"))
+			return InternalServerError(nil)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			rs.Handler(tc.h).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			if logged != rr.Body.Len() {
+				t.Errorf("Invalid logged byte count %d, want %d", logged, rr.Body.Len())
+			}
+		})
+	}
+}
+
+func TestHandlerOptionalInterfaces(t *testing.T) {
+	type features struct {
+		flusher, hijacker, pusher, readerFrom bool
+	}
+	check := func(w http.ResponseWriter) features {
+		var f features
+		_, f.flusher = w.(http.Flusher)
+		_, f.hijacker = w.(http.Hijacker)
+		_, f.pusher = w.(http.Pusher)
+		_, f.readerFrom = w.(io.ReaderFrom)
+		return f
+	}
+	var got, want features
+	done := make(chan struct{}, 1)
+	h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		defer func() { done <- struct{}{} }()
+		got = check(w)
+		if _, ok := w.(interface{ Unwrap() http.ResponseWriter }); !ok {
+			t.Error("Writer does not unwrap")
+		}
+		return nil
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want = check(w)
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	<-done
+	if !want.hijacker || got != want {
+		t.Errorf("Invalid optional interfaces %+v, want %+v", got, want)
+	}
+
+	rr := httptest.NewRecorder()
+	want = check(rr)
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	<-done
+	if got != want {
+		t.Errorf("Invalid optional interfaces %+v, want %+v", got, want)
+	}
+}
+
+func TestHandlerHijacked(t *testing.T) {
+	logged := make(chan error, 1)
+	rs := &Responder{
+		Logger: func(r *http.Request, err error, bytes int, dur time.Duration) {
+			logged <- err
+		},
+	}
+	srv := httptest.NewServer(rs.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return err
+		}
+		io.WriteString(conn, "HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+		conn.Close()
+		return InternalServerError(nil)
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Invalid status code %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if err := <-logged; StatusCode(err) != http.StatusInternalServerError {
+		t.Errorf("Error was not logged: %v", err)
+	}
+}
+
+func TestHandlerHeaderWritten(t *testing.T) {
+	var logs bytes.Buffer
+	logged := make(chan error, 1)
+	rs := &Responder{
+		Logger: func(r *http.Request, err error, bytes int, dur time.Duration) {
+			logged <- err
+		},
+	}
+	srv := httptest.NewUnstartedServer(rs.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return NotFound(nil)
+	}))
+	srv.Config.ErrorLog = log.New(&logs, "", 0)
+	srv.Start()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Invalid status code %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if len(body) != 0 {
+		t.Errorf("Invalid body %q, want none", body)
+	}
+	if err := <-logged; StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Error was not logged: %v", err)
+	}
+	srv.Close()
+	if strings.Contains(logs.String(), "superfluous") {
+		t.Errorf("Unexpected server warning: %s", logs.String())
+	}
+}
+
+func TestErrorHandlerFunc(t *testing.T) {
+	h := ErrorHandlerFunc(MethodNotAllowed(nil))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Invalid content type %q, want %q", got, "application/json")
+	}
+}
+
+// dbError is a driver error type
+type dbError struct{}
+
+func (*dbError) Error() string {
+	return "connection refused"
+}
+
+func TestLogCauseType(t *testing.T) {
+	err := NewCaller(http.StatusServiceUnavailable, errors.Errorf("Loading user: %w", &dbError{}))
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		want string
+	}{
+		{"default", &Responder{}, `503 Service Unavailable: "Loading user: connection refused"` + "\n"},
+		{"enabled", &Responder{LogCauseType: true}, `503 Service Unavailable: "Loading user: connection refused" (type: *httperr.dbError)` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var logged, plain string
+			tc.rs.Logger = func(r *http.Request, err error, bytes int, dur time.Duration) {
+				logged = fmt.Sprintf("%+v", err)
+				plain = fmt.Sprintf("%v", err)
+				if StatusCode(err) != http.StatusServiceUnavailable {
+					t.Errorf("Invalid logged status code %d", StatusCode(err))
+				}
+			}
+			tc.rs.Handler(func(w http.ResponseWriter, r *http.Request) error {
+				return err
+			}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if !strings.HasPrefix(logged, tc.want) || !strings.Contains(logged, "middleware_test.go:") {
+				t.Errorf("Invalid %%+v output %q, want prefix %q and the location", logged, tc.want)
+			}
+			if plain != err.Error() {
+				t.Errorf("Invalid %%v output %q, want %q", plain, err.Error())
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old/", NotFound(errors.New("Moved away")).(http.Handler))
+	r := httptest.NewRequest(http.MethodGet, "/old/page", nil)
+	r.Header.Set("Accept", "text/plain")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, r)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Invalid content type %q, want text/plain", got)
+	}
+	if !strings.Contains(rr.Body.String(), "Moved away") {
+		t.Errorf("Invalid body %q", rr.Body.String())
+	}
+}
+
+func TestCatch(t *testing.T) {
+	h := Catch(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Abort(New(http.StatusForbidden, errors.New("No access")))
+	}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	if !strings.Contains(rr.Body.String(), `"message":"No access"`) {
+		t.Errorf("Invalid body %s", rr.Body)
+	}
+	h = Catch(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("Invalid panic %v, want boom", p)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("Unrelated panic was recovered")
+}
+
+func TestRecovered(t *testing.T) {
+	cause := errors.New("Nil map")
+	for _, tc := range []struct {
+		name  string
+		panic interface{}
+		msg   string
+	}{
+		{"error", cause, "Nil map"},
+		{"value", 42, "Panic: 42"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			func() {
+				defer func() {
+					err = Recovered(recover())
+				}()
+				panic(tc.panic)
+			}()
+			if got := StatusCode(err); got != http.StatusInternalServerError {
+				t.Errorf("Invalid status code %d, want %d", got, http.StatusInternalServerError)
+			}
+			if got := Message(err); got != tc.msg {
+				t.Errorf("Invalid message %q, want %q", got, tc.msg)
+			}
+			if stack := Stack(err); !bytes.Contains(stack, []byte("TestRecovered")) {
+				t.Errorf("Invalid stack %s", stack)
+			}
+			if e, ok := tc.panic.(error); ok && !errors.Is(err, e) {
+				t.Errorf("Error %v does not wrap %v", err, e)
+			}
+		})
+	}
+}
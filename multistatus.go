@@ -0,0 +1,59 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ItemStatus is the status of a single item in a batch operation
+type ItemStatus struct {
+	ID         string `json:"id"`
+	StatusCode int    `json:"statusCode"`
+	Message    string `json:"message,omitempty"`
+}
+
+// NewItemStatus creates the status of an item from the error of its operation.
+// A nil error results in status 200.
+func NewItemStatus(id string, err error) ItemStatus {
+	if err == nil {
+		return ItemStatus{ID: id, StatusCode: http.StatusOK}
+	}
+	return ItemStatus{
+		ID:         id,
+		StatusCode: StatusCode(err),
+		Message:    Message(err),
+	}
+}
+
+// MultiStatus is an HTTP 207 response for batch operations with mixed results
+type MultiStatus struct {
+	Items []ItemStatus
+}
+
+// MultiStatusResponse creates an HTTP 207 response with the status of each item.
+// RespondJSON sends it with the items under "items".
+func MultiStatusResponse(items []ItemStatus) error {
+	return &MultiStatus{Items: items}
+}
+
+func (m *MultiStatus) Error() string {
+	return fmt.Sprintf("%d %s: %d items", http.StatusMultiStatus, http.StatusText(http.StatusMultiStatus), len(m.Items))
+}
+
+// StatusCode implements StatusCoder
+func (m *MultiStatus) StatusCode() int {
+	return http.StatusMultiStatus
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *MultiStatus) MarshalJSON() ([]byte, error) {
+	items := m.Items
+	if items == nil {
+		items = []ItemStatus{}
+	}
+	return json.Marshal(struct {
+		StatusCode int          `json:"statusCode"`
+		Items      []ItemStatus `json:"items"`
+	}{http.StatusMultiStatus, items})
+}
@@ -0,0 +1,25 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestMultiStatusResponse(t *testing.T) {
+	err := MultiStatusResponse([]ItemStatus{
+		NewItemStatus("a", nil),
+		NewItemStatus("b", NotFound(errors.New("No such item"))),
+	})
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, err)
+	if rr.Code != http.StatusMultiStatus {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusMultiStatus)
+	}
+	want := `{"statusCode":207,"items":[{"id":"a","statusCode":200},{"id":"b","statusCode":404,"message":"No such item"}]}` + "\n"
+	if got := rr.Body.String(); got != want {
+		t.Errorf("Invalid body %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+package httperr
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Renderer writes an error response in a specific media type
+type Renderer func(w http.ResponseWriter, err error) error
+
+var renderers = struct {
+	sync.RWMutex
+	m map[string]Renderer
+}{m: make(map[string]Renderer)}
+
+// RegisterRenderer registers a renderer for a media type used by Respond.
+// Registered renderers take precedence over the built in JSON, text and HTML ones.
+func RegisterRenderer(mediaType string, render Renderer) {
+	renderers.Lock()
+	defer renderers.Unlock()
+	renderers.m[mediaType] = render
+}
+
+// ResetRenderers removes all registered renderers
+func ResetRenderers() {
+	renderers.Lock()
+	defer renderers.Unlock()
+	renderers.m = make(map[string]Renderer)
+}
+
+var defaultRenderer = struct {
+	sync.RWMutex
+	render Renderer
+}{}
+
+// SetDefaultRenderer sets the renderer used by Respond and the middleware
+// when the request does not ask for a specific media type.
+// By default errors are sent as JSON.
+func SetDefaultRenderer(render Renderer) {
+	defaultRenderer.Lock()
+	defer defaultRenderer.Unlock()
+	defaultRenderer.render = render
+}
+
+// ResetDefaultRenderer restores sending errors as JSON by default
+func ResetDefaultRenderer() {
+	SetDefaultRenderer(nil)
+}
+
+// respondDefault responds with err using the default renderer or JSON if there is none
+func (rs *Responder) respondDefault(ctx context.Context, w http.ResponseWriter, err error) error {
+	defaultRenderer.RLock()
+	render := defaultRenderer.render
+	defaultRenderer.RUnlock()
+	if render == nil {
+		return rs.RespondJSONContext(ctx, w, err)
+	}
+	rs.recordError(err)
+	rs.setErrorHeaders(w, err)
+	return render(w, err)
+}
+
+func lookupRenderer(mediaType string) Renderer {
+	renderers.RLock()
+	defer renderers.RUnlock()
+	return renderers.m[mediaType]
+}
+
+// builtinMediaTypes are the media types Respond supports without a registered renderer
+var builtinMediaTypes = []string{"application/json", "text/plain", "text/html"}
+
+// Respond sends an HTTP response negotiating its media type using the default Responder
+func Respond(w http.ResponseWriter, r *http.Request, x interface{}) error {
+	return defaultResponder.Respond(w, r, x)
+}
+
+// Respond sends an HTTP response choosing the media type of errors from the request's Accept header.
+// Non error values are sent as JSON. Errors for requests without an acceptable media type
+// or accepting any are sent with the default renderer, see SetDefaultRenderer.
+// Errors with a status code registered with RegisterHandler are passed to its handler.
+func (rs *Responder) Respond(w http.ResponseWriter, r *http.Request, x interface{}) error {
+	err, ok := x.(error)
+	if !ok {
+		return rs.RespondJSONContext(r.Context(), w, x)
+	}
+	rs.setTraceParent(w, r)
+	if rs.dispatch(w, r, err) {
+		return nil
+	}
+	w.Header().Add("Vary", "Accept")
+	err = rs.translate(w, r, err)
+	mediaType := negotiate(r.Header.Get("Accept"))
+	if render := lookupRenderer(mediaType); render != nil {
+		rs.recordError(err)
+		rs.setErrorHeaders(w, err)
+		return render(w, err)
+	}
+	switch mediaType {
+	case "text/plain":
+		return rs.RespondText(w, err)
+	case "text/html":
+		return rs.RespondHTML(w, err)
+	case "application/json":
+		return rs.RespondJSONContext(r.Context(), w, err)
+	default:
+		return rs.respondDefault(r.Context(), w, err)
+	}
+}
+
+// translate replaces the message of err with its translation for the request languages
+// setting Content-Language to the language served
+func (rs *Responder) translate(w http.ResponseWriter, r *http.Request, err error) error {
+	if rs.Translate == nil {
+		return err
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	msg, lang, ok := rs.Translate(acceptLanguages(r.Header.Get("Accept-Language")), err)
+	if !ok {
+		return err
+	}
+	w.Header().Set("Content-Language", lang)
+	e, ok := err.(*httpError)
+	if ok {
+		e = e.clone()
+	} else {
+		e = &httpError{code: rs.statusCode(err), err: err}
+	}
+	e.message = msg
+	return e
+}
+
+// acceptLanguages returns the language tags of an Accept-Language header sorted by quality.
+// Tags with equal quality keep their order and tags with zero or invalid quality are dropped.
+func acceptLanguages(accept string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var langs []language
+	for _, part := range strings.Split(accept, ",") {
+		tag, q := part, ""
+		if i := strings.IndexByte(part, ';'); i != -1 {
+			tag, q = part[:i], strings.TrimSpace(part[i+1:])
+			if !strings.HasPrefix(q, "q=") {
+				continue
+			}
+			q = q[2:]
+		}
+		tag = strings.TrimSpace(tag)
+		v, ok := quality(q)
+		if tag == "" || !ok || v == 0 {
+			continue
+		}
+		langs = append(langs, language{tag: tag, q: v})
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+	tags := make([]string, len(langs))
+	for i, l := range langs {
+		tags[i] = l.tag
+	}
+	return tags
+}
+
+// negotiate returns the supported media type with the highest quality in an Accept header.
+// Ties are resolved in favor of JSON and then by order of appearance.
+// It returns "*/*" if any media type is acceptable and "" if none is supported.
+func negotiate(accept string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q, ok := quality(params["q"])
+		if !ok || q == 0 {
+			continue
+		}
+		t := matchMediaType(mediaType)
+		if t == "" {
+			continue
+		}
+		if q > bestQ || q == bestQ && t == "application/json" {
+			best, bestQ = t, q
+		}
+	}
+	return best
+}
+
+// quality parses an RFC 7231 quality value defaulting to 1
+func quality(q string) (float64, bool) {
+	if q == "" {
+		return 1, true
+	}
+	v, err := strconv.ParseFloat(q, 64)
+	if err != nil || v < 0 || v > 1 {
+		return 0, false
+	}
+	return v, true
+}
+
+// matchMediaType matches a possibly wildcard media type to a supported one
+func matchMediaType(mediaType string) string {
+	if mediaType == "*/*" {
+		return mediaType
+	}
+	if lookupRenderer(mediaType) != nil {
+		return mediaType
+	}
+	prefix := strings.TrimSuffix(mediaType, "*")
+	wildcard := prefix != mediaType
+	for _, t := range builtinMediaTypes {
+		if t == mediaType || wildcard && strings.HasPrefix(t, prefix) {
+			return t
+		}
+	}
+	return ""
+}
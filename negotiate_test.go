@@ -0,0 +1,159 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestRespondRegisteredRenderer(t *testing.T) {
+	defer ResetRenderers()
+	RegisterRenderer("application/x-yaml", func(w http.ResponseWriter, err error) error {
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.WriteHeader(StatusCode(err))
+		_, werr := fmt.Fprintf(w, "statusCode: %d\nmessage: %s\n", StatusCode(err), Message(err))
+		return werr
+	})
+	for _, tc := range []struct {
+		accept      string
+		contentType string
+	}{
+		{"application/x-yaml", "application/x-yaml"},
+		{"application/json;q=0.5, application/x-yaml", "application/x-yaml"},
+		{"application/json, application/x-yaml;q=0.5", "application/json"},
+	} {
+		t.Run(tc.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tc.accept)
+			rr := httptest.NewRecorder()
+			Respond(rr, r, NotFound(nil))
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("Invalid content type %q, want %q", got, tc.contentType)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"*/*", "*/*"},
+		{"text/html", "text/html"},
+		{"text/html;q=0.5, text/plain;q=0.8", "text/plain"},
+		{"text/plain, application/json", "application/json"},
+		{"text/html;q=0.9, application/json;q=0.9", "application/json"},
+		{"text/*;q=0.5, application/json;q=0.2", "text/plain"},
+		{"application/json;q=0, text/html", "text/html"},
+		{"application/json;q=2, text/html", "text/html"},
+		{"image/png", ""},
+	} {
+		if got := negotiate(tc.accept); got != tc.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestResetRenderers(t *testing.T) {
+	RegisterRenderer("application/x-yaml", func(w http.ResponseWriter, err error) error {
+		return RespondText(w, err)
+	})
+	ResetRenderers()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/x-yaml")
+	rr := httptest.NewRecorder()
+	Respond(rr, r, NotFound(nil))
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Invalid Content-Type %q after reset", got)
+	}
+}
+
+func TestRespondTranslate(t *testing.T) {
+	rs := &Responder{Translate: func(languages []string, err error) (string, string, bool) {
+		for _, lang := range languages {
+			if strings.HasPrefix(lang, "de") {
+				return "Nicht gefunden", lang, true
+			}
+		}
+		return "", "", false
+	}}
+	for _, tc := range []struct {
+		acceptLanguage string
+		message        string
+		lang           string
+	}{
+		{"fr;q=0.9, de-CH", "Nicht gefunden", "de-CH"},
+		{"fr", "No such user", ""},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", tc.acceptLanguage)
+		rr := httptest.NewRecorder()
+		rs.Respond(rr, r, NotFound(errors.New("No such user")))
+		if got := rr.Header().Get("Content-Language"); got != tc.lang {
+			t.Errorf("Invalid Content-Language %q for %q, want %q", got, tc.acceptLanguage, tc.lang)
+		}
+		var resp Response
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Message != tc.message {
+			t.Errorf("Invalid message %q for %q, want %q", resp.Message, tc.acceptLanguage, tc.message)
+		}
+		if vary := rr.Header()["Vary"]; len(vary) != 2 || vary[1] != "Accept-Language" {
+			t.Errorf("Invalid Vary headers %q", vary)
+		}
+	}
+}
+
+func TestSetDefaultRenderer(t *testing.T) {
+	defer ResetDefaultRenderer()
+	var rendered error
+	SetDefaultRenderer(func(w http.ResponseWriter, err error) error {
+		rendered = err
+		return RespondText(w, err)
+	})
+	err := NotFound(errors.New("No such user"))
+	rr := httptest.NewRecorder()
+	Respond(rr, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if rendered != err {
+		t.Errorf("Default renderer was not invoked with %v", err)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Invalid Content-Type %q", got)
+	}
+	ResetDefaultRenderer()
+	rr = httptest.NewRecorder()
+	Respond(rr, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Invalid Content-Type %q after reset", got)
+	}
+}
+
+func TestRespondTranslateDefaultStatusCode(t *testing.T) {
+	rs := &Responder{
+		DefaultStatusCode: http.StatusBadGateway,
+		Translate: func(languages []string, err error) (string, string, bool) {
+			if len(languages) > 0 && languages[0] == "de" {
+				return "Upstream fehlgeschlagen", "de", true
+			}
+			return "", "", false
+		},
+	}
+	for _, lang := range []string{"de", "fr"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", lang)
+		rr := httptest.NewRecorder()
+		rs.Respond(rr, r, errors.New("Upstream failed"))
+		if rr.Code != http.StatusBadGateway {
+			t.Errorf("Invalid status code %d for %q, want %d", rr.Code, lang, http.StatusBadGateway)
+		}
+	}
+}
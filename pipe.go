@@ -0,0 +1,15 @@
+//go:build !plan9
+// +build !plan9
+
+package httperr
+
+import (
+	"syscall"
+
+	errors "golang.org/x/xerrors"
+)
+
+// isBrokenPipe checks if err is caused by the peer closing the connection
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
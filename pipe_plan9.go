@@ -0,0 +1,7 @@
+package httperr
+
+// isBrokenPipe checks if err is caused by the peer closing the connection.
+// Plan 9 has no errno values for it.
+func isBrokenPipe(err error) bool {
+	return false
+}
@@ -0,0 +1,46 @@
+//go:build !plan9
+// +build !plan9
+
+package httperr
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+// brokenPipeWriter fails writes like a connection closed by the client
+type brokenPipeWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w brokenPipeWriter) Write(p []byte) (int, error) {
+	return 0, &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+}
+
+func (w brokenPipeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func TestRespondJSONBrokenPipe(t *testing.T) {
+	err := RespondJSON(brokenPipeWriter{httptest.NewRecorder()}, NotFound(nil))
+	if !errors.Is(err, ErrClientDisconnected) {
+		t.Errorf("Invalid error %v, want ErrClientDisconnected", err)
+	}
+	if !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("Error %v does not wrap the write error", err)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "write" {
+		t.Errorf("Error %v does not wrap the *net.OpError", err)
+	}
+	if err := RespondRaw(brokenPipeWriter{httptest.NewRecorder()}, http.StatusOK, "text/plain", strings.NewReader("ok")); !errors.Is(err, ErrClientDisconnected) {
+		t.Errorf("Invalid error %v from RespondRaw, want ErrClientDisconnected", err)
+	}
+}
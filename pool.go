@@ -0,0 +1,34 @@
+package httperr
+
+import "sync"
+
+var errorPool = sync.Pool{
+	New: func() interface{} {
+		return new(httpError)
+	},
+}
+
+// Acquire gets an HTTP error from a pool.
+//
+// It is meant for hot paths where the error does not escape past the response.
+// The error must be passed to Release once it is no longer used.
+// Retaining or using the error after Release is unsafe as it may be reused at any time.
+// Use New for errors whose lifetime is not strictly controlled.
+func Acquire(code int, err error) error {
+	e := errorPool.Get().(*httpError)
+	e.code = code
+	e.err = err
+	e.pooled = true
+	return e
+}
+
+// Release resets an error obtained with Acquire and returns it to the pool.
+// Errors not obtained with Acquire, including those created with New, are ignored.
+func Release(err error) {
+	e, ok := err.(*httpError)
+	if !ok || e == nil || !e.pooled {
+		return
+	}
+	*e = httpError{}
+	errorPool.Put(e)
+}
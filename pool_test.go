@@ -0,0 +1,70 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+var errPoolCause = errors.New("Pool cause")
+
+func TestAcquire(t *testing.T) {
+	err := Acquire(http.StatusNotFound, errPoolCause)
+	if code := StatusCode(err); code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", code, http.StatusNotFound)
+	}
+	if !errors.Is(err, errPoolCause) {
+		t.Errorf("Acquired error %v does not wrap its cause", err)
+	}
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, err)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid response status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	Release(err)
+	if e := err.(*httpError); e.code != 0 || e.err != nil {
+		t.Errorf("Released error was not reset: %v", e)
+	}
+}
+
+func TestReleaseForeign(t *testing.T) {
+	Release(nil)
+	Release(errPoolCause)
+	var e *httpError
+	Release(e)
+}
+
+func TestReleaseNotPooled(t *testing.T) {
+	err := NotFound(errPoolCause)
+	Release(err)
+	if code := StatusCode(err); code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d after Release, want %d", code, http.StatusNotFound)
+	}
+	if !errors.Is(err, errPoolCause) {
+		t.Errorf("Released error %v lost its cause", err)
+	}
+	err = WithHeader(Acquire(http.StatusNotFound, errPoolCause), "X-Request-Id", "abc")
+	Release(err)
+	if code := StatusCode(err); code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d for a released copy, want %d", code, http.StatusNotFound)
+	}
+}
+
+func BenchmarkAcquire(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := Acquire(http.StatusNotFound, errPoolCause)
+		Release(err)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	var err error
+	for i := 0; i < b.N; i++ {
+		err = New(http.StatusNotFound, errPoolCause)
+	}
+	_ = err
+}
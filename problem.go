@@ -0,0 +1,162 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	errors "golang.org/x/xerrors"
+)
+
+// WithType returns a copy of err with a URI identifying its type.
+// The type is included in error bodies so that clients can link to its documentation.
+// An empty uri leaves err unchanged.
+// It returns nil if err is nil.
+func WithType(err error, uri string) error {
+	if err == nil {
+		return nil
+	}
+	if uri == "" {
+		return err
+	}
+	e := with(err)
+	e.typeURI = uri
+	return e
+}
+
+// Type returns the type URI of the outermost error in the chain that has one
+func Type(err error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*httpError); ok && e.typeURI != "" {
+			return e.typeURI
+		}
+	}
+	return ""
+}
+
+// ProblemResponse is an RFC 7807 problem details message
+type ProblemResponse struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions are additional members of the problem object
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// problemKeys are the JSON keys of the standard ProblemResponse fields
+var problemKeys = jsonKeys(ProblemResponse{})
+
+// MarshalJSON implements json.Marshaler.
+// Like Response, extension members follow the standard fields in sorted key order.
+func (p ProblemResponse) MarshalJSON() ([]byte, error) {
+	type problem ProblemResponse
+	data, err := marshalJSON(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	return appendMembers(data, p.Extensions, problemKeys)
+}
+
+// NewProblemResponse creates the problem details message for an error
+func NewProblemResponse(err error) *ProblemResponse {
+	return defaultResponder.problem(context.Background(), StatusCode(err), err)
+}
+
+// problem builds the ProblemResponse for an error from its Response.
+// The detail is omitted if it repeats the title and validation messages,
+// trace IDs and verbose details become extension members.
+func (rs *Responder) problem(ctx context.Context, code int, err error) *ProblemResponse {
+	resp := rs.response(ctx, code, err)
+	p := &ProblemResponse{
+		Type:   resp.Type,
+		Title:  resp.Error,
+		Status: resp.StatusCode,
+	}
+	if resp.Message != resp.Error {
+		p.Detail = resp.Message
+	}
+	ext := make(map[string]interface{}, len(resp.Extensions)+4)
+	for k, v := range resp.Extensions {
+		ext[k] = v
+	}
+	if len(resp.Validation) > 0 {
+		ext["validation"] = resp.Validation
+	}
+	if resp.TraceID != "" {
+		ext["traceId"] = resp.TraceID
+	}
+	if len(resp.Fields) > 0 {
+		ext["fields"] = resp.Fields
+	}
+	if len(resp.Stack) > 0 {
+		ext["stack"] = resp.Stack
+	}
+	if len(ext) > 0 {
+		p.Extensions = ext
+	}
+	return p
+}
+
+// RespondProblem sends an RFC 7807 problem details response using the default Responder
+func RespondProblem(w http.ResponseWriter, err error) error {
+	return defaultResponder.RespondProblem(w, err)
+}
+
+// RespondProblem sends an RFC 7807 problem details response with Content-Type application/problem+json
+func (rs *Responder) RespondProblem(w http.ResponseWriter, err error) error {
+	code := rs.statusCode(err)
+	rs.recordError(err)
+	rs.setErrorHeaders(w, err)
+	var buf bytes.Buffer
+	enc := rs.newEncoder(&buf)
+	if err := enc.Encode(rs.problem(context.Background(), code, err)); err != nil {
+		return err
+	}
+	return writeBody(w, code, "application/problem+json", buf.Bytes())
+}
+
+// RespondProblemList sends several errors as a JSON array of problem details using the default Responder
+func RespondProblemList(w http.ResponseWriter, errs []error) error {
+	return defaultResponder.RespondProblemList(w, errs)
+}
+
+// RespondProblemList sends several errors as a JSON array of RFC 7807 problem details.
+// The response status is the most severe one, that is the status of the first
+// server error or the first client error if there are no server errors.
+// The headers of all errors are set on the response. Nil errors are skipped
+// and an empty array is sent with status 200 if there are no errors.
+func (rs *Responder) RespondProblemList(w http.ResponseWriter, errs []error) error {
+	code := http.StatusOK
+	problems := make([]*ProblemResponse, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		c := rs.statusCode(err)
+		if severity(c) > severity(code) {
+			code = c
+		}
+		rs.recordError(err)
+		rs.setErrorHeaders(w, err)
+		problems = append(problems, rs.problem(context.Background(), c, err))
+	}
+	var buf bytes.Buffer
+	if err := rs.newEncoder(&buf).Encode(problems); err != nil {
+		return err
+	}
+	return writeBody(w, code, "application/problem+json", buf.Bytes())
+}
+
+// severity ranks status codes by class, server errors being the most severe
+func severity(code int) int {
+	switch {
+	case IsServerError(code):
+		return 2
+	case IsClientError(code):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,67 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestWithType(t *testing.T) {
+	const uri = "https://example.com/errors/out-of-credit"
+	err := WithType(PaymentRequired(errors.New("Not enough credit")), uri)
+
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, err)
+	var resp Response
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Type != uri {
+		t.Errorf("Invalid type %q in JSON body, want %q", resp.Type, uri)
+	}
+
+	rr = httptest.NewRecorder()
+	RespondProblem(rr, err)
+	var p ProblemResponse
+	json.Unmarshal(rr.Body.Bytes(), &p)
+	if p.Type != uri {
+		t.Errorf("Invalid type %q in problem body, want %q", p.Type, uri)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Invalid content type %q", got)
+	}
+	if p.Status != http.StatusPaymentRequired || p.Detail != "Not enough credit" {
+		t.Errorf("Invalid problem %+v", p)
+	}
+
+	plain := NotFound(nil)
+	if WithType(plain, "") != plain {
+		t.Error("Empty type changed the error")
+	}
+}
+
+func TestRespondProblemList(t *testing.T) {
+	rr := httptest.NewRecorder()
+	RespondProblemList(rr, []error{
+		NotFound(errors.New("No such user")),
+		nil,
+		ServiceUnavailable(errors.New("Database is down")),
+	})
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Invalid Content-Type %q", got)
+	}
+	var problems []ProblemResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &problems); err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Invalid number of problems %d, want 2", len(problems))
+	}
+	if problems[0].Status != http.StatusNotFound || problems[1].Status != http.StatusServiceUnavailable {
+		t.Errorf("Invalid problem statuses %d, %d", problems[0].Status, problems[1].Status)
+	}
+}
@@ -0,0 +1,59 @@
+package httperr
+
+import (
+	"net/http"
+	"sync"
+
+	errors "golang.org/x/xerrors"
+)
+
+var registry struct {
+	sync.RWMutex
+	entries []registryEntry
+}
+
+type registryEntry struct {
+	sentinel error
+	code     int
+}
+
+// Register maps a sentinel error to an HTTP status code for Map.
+// Registering a sentinel again replaces its code.
+func Register(sentinel error, code int) {
+	registry.Lock()
+	defer registry.Unlock()
+	for i := range registry.entries {
+		if registry.entries[i].sentinel == sentinel {
+			registry.entries[i].code = code
+			return
+		}
+	}
+	registry.entries = append(registry.entries, registryEntry{sentinel: sentinel, code: code})
+}
+
+// Map wraps err with the status code of the first registered sentinel it matches with errors.Is.
+// Errors that match no sentinel keep the code of a StatusCoder in the chain or become HTTP 500 errors.
+func Map(err error) error {
+	if err == nil {
+		return nil
+	}
+	if code := lookupCode(err); code != 0 {
+		return New(code, err)
+	}
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		return err
+	}
+	return New(http.StatusInternalServerError, err)
+}
+
+func lookupCode(err error) int {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, entry := range registry.entries {
+		if errors.Is(err, entry.sentinel) {
+			return entry.code
+		}
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package httperr
+
+import (
+	"net/http"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+var (
+	errRegistryNotFound = errors.New("User not found")
+	errRegistryConflict = errors.New("User exists")
+)
+
+func TestMap(t *testing.T) {
+	Register(errRegistryNotFound, http.StatusNotFound)
+	Register(errRegistryConflict, http.StatusBadRequest)
+	Register(errRegistryConflict, http.StatusConflict)
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"sentinel", errRegistryNotFound, http.StatusNotFound},
+		{"wrapped", errors.Errorf("Loading user: %w", errRegistryNotFound), http.StatusNotFound},
+		{"replaced", errors.Errorf("Saving user: %w", errRegistryConflict), http.StatusConflict},
+		{"coder", BadRequest(errors.New("Missing name")), http.StatusBadRequest},
+		{"unknown", errors.New("Unknown"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Map(tc.err)
+			if code := StatusCode(err); code != tc.want {
+				t.Errorf("Invalid status code %d, want %d", code, tc.want)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Mapped error %v does not wrap %v", err, tc.err)
+			}
+		})
+	}
+	if err := Map(nil); err != nil {
+		t.Errorf("Invalid error %v for nil, want nil", err)
+	}
+}
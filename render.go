@@ -0,0 +1,83 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+
+	errors "golang.org/x/xerrors"
+)
+
+// RespondText sends a plain text error response using the default Responder
+func RespondText(w http.ResponseWriter, err error) error {
+	return defaultResponder.RespondText(w, err)
+}
+
+// RespondText sends a plain text error response with the error message as body
+func (rs *Responder) RespondText(w http.ResponseWriter, err error) error {
+	code := rs.statusCode(err)
+	rs.recordError(err)
+	rs.setErrorHeaders(w, err)
+	resp := rs.response(context.Background(), code, err)
+	return writeBody(w, code, "text/plain; charset=utf-8", []byte(resp.Message+"\n"))
+}
+
+// Error replies to a request with the message of err as plain text like http.Error,
+// resolving the status code from err.
+// It sets X-Content-Type-Options to nosniff in addition to the error headers.
+func Error(w http.ResponseWriter, err error) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	RespondText(w, err)
+}
+
+// RespondHTML sends an HTML error response using the default Responder
+func RespondHTML(w http.ResponseWriter, err error) error {
+	return defaultResponder.RespondHTML(w, err)
+}
+
+// defaultTemplate renders HTML error pages
+var defaultTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.StatusCode}} {{.Error}}</title></head>
+<body>
+<h1>{{.StatusCode}} {{.Error}}</h1>
+<p>{{.Message}}</p>
+</body>
+</html>
+`))
+
+// RespondHTML sends an HTML error response.
+// The page is rendered by executing Responder.Template with the error's Response.
+// If the template fails a minimal inline page is sent instead and a TemplateError is returned.
+func (rs *Responder) RespondHTML(w http.ResponseWriter, err error) error {
+	code := rs.statusCode(err)
+	rs.recordError(err)
+	rs.setErrorHeaders(w, err)
+	resp := rs.response(context.Background(), code, err)
+	tpl := rs.Template
+	if tpl == nil {
+		tpl = defaultTemplate
+	}
+	var buf bytes.Buffer
+	tplErr := tpl.Execute(&buf, resp)
+	if tplErr != nil {
+		buf.Reset()
+		fmt.Fprintf(&buf, "<!DOCTYPE html>\n<h1>%d %s</h1>\n", code, html.EscapeString(resp.Error))
+	}
+	if err := writeBody(w, code, "text/html; charset=utf-8", buf.Bytes()); err != nil {
+		return err
+	}
+	return TemplateError(tplErr)
+}
+
+// TemplateError creates an HTTP 500 error for a failed template execution wrapping err.
+// It returns nil if err is nil.
+func TemplateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return InternalServerError(errors.Errorf("Failed to render template: %w", err))
+}
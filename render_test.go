@@ -0,0 +1,61 @@
+package httperr
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestError(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Error(rr, NotFound(errors.New("No such user")))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if got := rr.Body.String(); got != "No such user\n" {
+		t.Errorf("Invalid body %q", got)
+	}
+	h := rr.Header()
+	if got := h.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Invalid Content-Type %q", got)
+	}
+	if got := h.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("Invalid X-Content-Type-Options %q", got)
+	}
+}
+
+func TestRespondHTMLTemplateError(t *testing.T) {
+	rs := &Responder{Template: template.Must(template.New("error").Parse(`{{.Missing}}`))}
+	rr := httptest.NewRecorder()
+	err := rs.RespondHTML(rr, NotFound(errors.New("No such user")))
+	if got := StatusCode(err); got != http.StatusInternalServerError {
+		t.Errorf("Invalid template error status code %d, want %d", got, http.StatusInternalServerError)
+	}
+	if msg := Message(err); !strings.HasPrefix(msg, "Failed to render template: ") || !strings.Contains(msg, "Missing") {
+		t.Errorf("Template error %q does not wrap the execution error", msg)
+	}
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if got := rr.Body.String(); !strings.Contains(got, "<h1>404 Not Found</h1>") {
+		t.Errorf("Invalid fallback body %q", got)
+	}
+}
+
+func TestTemplateError(t *testing.T) {
+	if err := TemplateError(nil); err != nil {
+		t.Errorf("Invalid error %v for nil", err)
+	}
+	tplErr := errors.New("Template failed")
+	err := TemplateError(tplErr)
+	if got := StatusCode(err); got != http.StatusInternalServerError {
+		t.Errorf("Invalid status code %d, want %d", got, http.StatusInternalServerError)
+	}
+	if !errors.Is(err, tplErr) {
+		t.Errorf("Error %v does not wrap %v", err, tplErr)
+	}
+}
@@ -0,0 +1,63 @@
+package httperr
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RedactQueryParams are the names of query parameters masked by WithRequest, compared case insensitively.
+// It can be replaced during initialization.
+var RedactQueryParams = []string{"token", "password", "secret", "key"}
+
+// redacted replaces the values of redacted query parameters
+const redacted = "REDACTED"
+
+// WithRequest returns a copy of err with the method and URL of the request that caused it
+// as the "method" and "url" detail fields.
+// Values of query parameters in RedactQueryParams and URL passwords are masked.
+// It returns nil if err is nil.
+func WithRequest(err error, r *http.Request) error {
+	if err == nil || r == nil || r.URL == nil {
+		return err
+	}
+	err = WithField(err, "method", r.Method)
+	return WithField(err, "url", RedactURL(r.URL))
+}
+
+// RedactURL returns u as a string masking its password and the values of query parameters in RedactQueryParams
+func RedactURL(u *url.URL) string {
+	c := *u
+	if c.User != nil {
+		if _, ok := c.User.Password(); ok {
+			c.User = url.UserPassword(c.User.Username(), redacted)
+		}
+	}
+	if c.RawQuery != "" {
+		q := c.Query()
+		changed := false
+		for name, values := range q {
+			if !isRedacted(name) {
+				continue
+			}
+			for i := range values {
+				values[i] = redacted
+			}
+			changed = true
+		}
+		if changed {
+			c.RawQuery = q.Encode()
+		}
+	}
+	return c.String()
+}
+
+// isRedacted checks if a query parameter is in RedactQueryParams
+func isRedacted(name string) bool {
+	for _, p := range RedactQueryParams {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
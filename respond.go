@@ -0,0 +1,494 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	errors "golang.org/x/xerrors"
+)
+
+// Responder sends HTTP responses.
+// The zero value is ready to use, NewResponder creates one from options.
+// A Responder must not be modified while in use or copied after first use.
+type Responder struct {
+	// Success wraps bodies in an envelope with a top-level "success" field.
+	// Errors are placed under "error" and other values under "data".
+	Success bool
+	// PanicMapper translates values recovered from panics in Recover to errors.
+	// Panics it maps to nil are sent as HTTP 500 errors.
+	PanicMapper func(recovered interface{}) error
+	// TraceID extracts a trace ID from the context to add to error bodies.
+	// See the httperrotel package for an OpenTelemetry implementation.
+	TraceID func(ctx context.Context) string
+	// ReportElapsed adds the time elapsed before an error occurred to error bodies as "timeoutMs".
+	// See FromContextSince.
+	ReportElapsed bool
+	// Logger is called by the Handler and Recover middleware for each error response
+	// with the number of bytes written and the time spent handling the request.
+	Logger func(r *http.Request, err error, bytes int, dur time.Duration)
+	// LogCauseType appends the type of the innermost cause to the %+v format
+	// of errors passed to the Logger, ie "(type: *pq.Error)"
+	LogCauseType bool
+	// Template renders the HTML error pages of RespondHTML with the error's Response.
+	Template *template.Template
+	// Verbosity controls the detail of error bodies
+	Verbosity Verbosity
+	// Headers are set on every error response.
+	// If nil, X-Content-Type-Options is set to nosniff.
+	// Set it to an empty http.Header to disable default headers.
+	Headers http.Header
+	// RetryAfterDate sends Retry-After headers as an HTTP-date instead of seconds
+	RetryAfterDate bool
+	// Now returns the current time, it defaults to time.Now
+	Now func() time.Time
+	// DisableHTMLEscape leaves <, > and & unescaped in JSON bodies
+	DisableHTMLEscape bool
+	// DefaultStatusCode is used for errors that have no StatusCoder in their chain.
+	// It defaults to http.StatusInternalServerError.
+	DefaultStatusCode int
+	// Fallback responds when encoding a JSON body fails with the encoding error.
+	// By default a minimal HTTP 500 JSON body is sent.
+	Fallback Renderer
+	// SampleRate is the fraction of verbose responses for the Sampled verbosity
+	SampleRate float64
+	// Rand returns a random number in [0, 1) for sampling, it defaults to math/rand.Float64
+	Rand func() float64
+	// OmitErrorField drops the "error" status text field from JSON error bodies
+	OmitErrorField bool
+	// Indent pretty-prints JSON bodies indenting nested values with it, ie two spaces.
+	// Bodies are compact if it is empty.
+	Indent string
+	// Translate localizes the message of an error for the languages of a request's
+	// Accept-Language header, given in order of preference.
+	// It returns the message and the language tag served or false if there is no translation.
+	// Translation is only done by Respond, which also sets Content-Language.
+	Translate func(languages []string, err error) (message, lang string, ok bool)
+	// TraceParent echoes the W3C Trace Context traceparent header of requests on error responses
+	// sent by Respond and the middleware, generating one if the request has none
+	TraceParent bool
+	// MaxMessageLength truncates error messages longer than it in bytes, adding an ellipsis.
+	// Messages are not truncated if it is zero.
+	MaxMessageLength int
+	// CacheControl returns the Cache-Control header of error responses by status code.
+	// If nil, errors are sent with no-store so that intermediaries do not cache them.
+	// Returning "" sends no Cache-Control header, ie to allow caching 404 and 410 errors.
+	CacheControl func(code int) string
+	// OmitStatusCodeField drops the "statusCode" field from JSON error bodies
+	OmitStatusCodeField bool
+	// RecordLastError keeps the most recent error rendered for LastError
+	RecordLastError bool
+
+	last lastError
+}
+
+// fallbackBody is sent when encoding a JSON body fails
+var fallbackBody = []byte(`{"statusCode":500,"error":"Internal Server Error"}` + "\n")
+
+// Option configures a Responder
+type Option func(rs *Responder)
+
+// NewResponder creates a Responder applying options in order
+func NewResponder(options ...Option) *Responder {
+	rs := &Responder{}
+	for _, option := range options {
+		option(rs)
+	}
+	return rs
+}
+
+// WithLogger sets the Responder.Logger
+func WithLogger(logger func(r *http.Request, err error, bytes int, dur time.Duration)) Option {
+	return func(rs *Responder) {
+		rs.Logger = logger
+	}
+}
+
+// WithVerbosity sets the Responder.Verbosity
+func WithVerbosity(v Verbosity) Option {
+	return func(rs *Responder) {
+		rs.Verbosity = v
+	}
+}
+
+// WithDefaultStatusCode sets the Responder.DefaultStatusCode
+func WithDefaultStatusCode(code int) Option {
+	return func(rs *Responder) {
+		rs.DefaultStatusCode = code
+	}
+}
+
+// Verbosity is the level of detail in error bodies
+type Verbosity int
+
+// Verbosity levels
+const (
+	// Standard bodies include the error message
+	Standard Verbosity = iota
+	// Minimal bodies only include the status text
+	Minimal
+	// Verbose bodies also include the error fields and the locations where errors were created
+	Verbose
+	// Sampled bodies are verbose for a Responder.SampleRate fraction of responses and minimal otherwise
+	Sampled
+)
+
+var defaultResponder = &Responder{}
+
+// envelope wraps a response body when Responder.Success is set
+type envelope struct {
+	Success bool        `json:"success"`
+	Error   interface{} `json:"error,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// RespondJSON sends a JSON encoded HTTP response using the default Responder
+func RespondJSON(w http.ResponseWriter, x interface{}) error {
+	return defaultResponder.RespondJSON(w, x)
+}
+
+// RespondJSONContext sends a JSON encoded HTTP response using the default Responder
+func RespondJSONContext(ctx context.Context, w http.ResponseWriter, x interface{}) error {
+	return defaultResponder.RespondJSONContext(ctx, w, x)
+}
+
+// RespondJSON sends a JSON encoded HTTP response.
+// If x is an error the status code is resolved with StatusCode.
+// A nil x is sent as a "null" body with status 200.
+// A *ResultBuilder is sent as its fatal error or payload and warnings.
+// The body is buffered so that Content-Length can be set.
+func (rs *Responder) RespondJSON(w http.ResponseWriter, x interface{}) error {
+	return rs.RespondJSONContext(context.Background(), w, x)
+}
+
+// RespondJSONContext sends a JSON encoded HTTP response like RespondJSON.
+// The context is used to resolve the trace ID of error responses.
+func (rs *Responder) RespondJSONContext(ctx context.Context, w http.ResponseWriter, x interface{}) error {
+	if b, ok := x.(*ResultBuilder); ok {
+		x = b.result()
+	}
+	code := http.StatusOK
+	if err, ok := x.(error); ok {
+		code = rs.statusCode(err)
+		rs.recordError(err)
+		rs.setErrorHeaders(w, err)
+		body := rs.errorBody(ctx, code, err)
+		if rs.Success {
+			body = envelope{Error: body}
+		}
+		x = body
+	} else if rs.Success {
+		x = envelope{Success: true, Data: x}
+	}
+	var buf bytes.Buffer
+	enc := rs.newEncoder(&buf)
+	if err := enc.Encode(x); err != nil {
+		if rs.Fallback != nil {
+			rs.Fallback(w, err)
+		} else {
+			writeBody(w, http.StatusInternalServerError, "application/json", fallbackBody)
+		}
+		return err
+	}
+	return writeBody(w, code, "application/json", buf.Bytes())
+}
+
+// RespondJSONObject sends a JSON object with an error merged in using the default Responder
+func RespondJSONObject(w http.ResponseWriter, obj map[string]interface{}, err error) error {
+	return defaultResponder.RespondJSONObject(w, obj, err)
+}
+
+// RespondJSONObject sends obj as a JSON object with the error body of err under "error".
+// The status code is resolved from err like RespondJSON and obj is not modified.
+// A nil err sends obj as is with status 200.
+func (rs *Responder) RespondJSONObject(w http.ResponseWriter, obj map[string]interface{}, err error) error {
+	code := http.StatusOK
+	body := make(map[string]interface{}, len(obj)+1)
+	for k, v := range obj {
+		body[k] = v
+	}
+	if err != nil {
+		code = rs.statusCode(err)
+		rs.recordError(err)
+		rs.setErrorHeaders(w, err)
+		body["error"] = rs.errorBody(context.Background(), code, err)
+	}
+	var buf bytes.Buffer
+	if err := rs.newEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	return writeBody(w, code, "application/json", buf.Bytes())
+}
+
+// newEncoder creates a JSON encoder applying the Responder encoding options
+func (rs *Responder) newEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!rs.DisableHTMLEscape)
+	if rs.Indent != "" {
+		enc.SetIndent("", rs.Indent)
+	}
+	return enc
+}
+
+// writeBody writes a buffered response body setting Content-Type and Content-Length.
+// The body is skipped if the status code does not allow one.
+func writeBody(w http.ResponseWriter, code int, contentType string, body []byte) error {
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	if !BodyAllowed(code) {
+		w.WriteHeader(code)
+		return nil
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(code)
+	_, err := w.Write(body)
+	return writeError(err)
+}
+
+// ErrClientDisconnected is returned when writing a response fails because the client went away.
+// Middleware can use it with errors.Is to tell disconnections apart from server faults,
+// the original write error is kept in the chain.
+var ErrClientDisconnected = errors.New("Client disconnected")
+
+// writeError converts errors writing a response due to a client disconnection to ErrClientDisconnected
+func writeError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), isBrokenPipe(err):
+		return &disconnectError{err: err}
+	default:
+		return err
+	}
+}
+
+// disconnectError is an error writing a response because the client went away
+type disconnectError struct {
+	err error
+}
+
+func (e *disconnectError) Error() string {
+	return ErrClientDisconnected.Error() + ": " + e.err.Error()
+}
+
+// Is reports the error as ErrClientDisconnected
+func (e *disconnectError) Is(target error) bool {
+	return target == ErrClientDisconnected
+}
+
+func (e *disconnectError) Unwrap() error {
+	return e.err
+}
+
+// RespondResponse sends a pre-built Response as JSON using its StatusCode as the HTTP status.
+// A zero StatusCode is sent as HTTP 500. Error responses get the default Responder headers.
+func RespondResponse(w http.ResponseWriter, resp *Response) error {
+	r := *resp
+	if r.StatusCode == 0 {
+		r.StatusCode = http.StatusInternalServerError
+	}
+	if IsError(r.StatusCode) {
+		defaultResponder.setDefaultHeaders(w.Header(), r.StatusCode)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(r); err != nil {
+		return err
+	}
+	return writeBody(w, r.StatusCode, "application/json", buf.Bytes())
+}
+
+// RespondRaw sends a pre-serialized HTTP response copying body to w.
+// The body is not written if the status code does not allow one.
+// Error responses get the default Responder headers.
+func RespondRaw(w http.ResponseWriter, code int, contentType string, body io.Reader) error {
+	if IsError(code) {
+		defaultResponder.setDefaultHeaders(w.Header(), code)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	if !BodyAllowed(code) || body == nil {
+		return nil
+	}
+	_, err := io.Copy(w, body)
+	return writeError(err)
+}
+
+// errorBody returns the JSON body of an error response.
+// Errors implementing json.Marshaler are encoded as is.
+func (rs *Responder) errorBody(ctx context.Context, code int, err error) interface{} {
+	if _, ok := err.(*httpError); !ok {
+		if _, ok := err.(json.Marshaler); ok {
+			return err
+		}
+	}
+	resp := rs.response(ctx, code, err)
+	if !rs.OmitErrorField && !rs.OmitStatusCodeField {
+		return resp
+	}
+	return &omitMembers{resp: resp, keys: map[string]bool{
+		"error":      rs.OmitErrorField,
+		"statusCode": rs.OmitStatusCodeField,
+	}}
+}
+
+// response builds the Response for an error applying the Responder options
+func (rs *Responder) response(ctx context.Context, code int, err error) *Response {
+	e, ok := err.(*httpError)
+	if !ok {
+		e = &httpError{code: code, err: err}
+	}
+	resp := e.response()
+	resp.Message = validUTF8(resp.Message)
+	resp.Error = validUTF8(resp.Error)
+	if IsInternal(err) {
+		resp.Message = resp.Error
+	}
+	if rs.MaxMessageLength > 0 {
+		resp.Message = truncate(resp.Message, rs.MaxMessageLength)
+	}
+	switch rs.verbosity() {
+	case Minimal:
+		resp.Message = resp.Error
+		resp.Validation = nil
+		resp.Extensions = nil
+	case Verbose:
+		resp.Fields = Fields(err)
+		resp.Stack = locations(err)
+	}
+	if rs.TraceID != nil {
+		resp.TraceID = rs.TraceID(ctx)
+	}
+	if rs.ReportElapsed {
+		resp.TimeoutMs = int64(e.elapsed / time.Millisecond)
+	}
+	return &resp
+}
+
+// defaultErrorHeaders are set on error responses if Responder.Headers is nil
+var defaultErrorHeaders = http.Header{
+	"X-Content-Type-Options": {"nosniff"},
+}
+
+// SetErrorHeaders sets the error headers of the default Responder and the HTTP headers and cookies an error carries.
+// It is meant for custom response encoders.
+func SetErrorHeaders(w http.ResponseWriter, err error) {
+	defaultResponder.setErrorHeaders(w, err)
+}
+
+// setErrorHeaders sets the default error headers and the HTTP headers and cookies an error carries
+func (rs *Responder) setErrorHeaders(w http.ResponseWriter, err error) {
+	h := w.Header()
+	rs.setDefaultHeaders(h, rs.statusCode(err))
+	for k, v := range CollectHeaders(err) {
+		h[k] = v
+	}
+	if d := retryAfter(err, rs.now()); d > 0 {
+		if rs.RetryAfterDate {
+			h.Set("Retry-After", rs.now().Add(d).UTC().Format(http.TimeFormat))
+		} else {
+			h.Set("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+		}
+	}
+	for _, c := range Cookies(err) {
+		if v := c.String(); v != "" && !hasValue(h["Set-Cookie"], v) {
+			h.Add("Set-Cookie", v)
+		}
+	}
+}
+
+// setDefaultHeaders sets the Responder headers and the Cache-Control header of error status codes
+func (rs *Responder) setDefaultHeaders(h http.Header, code int) {
+	headers := rs.Headers
+	if headers == nil {
+		headers = defaultErrorHeaders
+	}
+	for k, v := range headers {
+		h[k] = append([]string(nil), v...)
+	}
+	if !IsError(code) {
+		return
+	}
+	if cc := rs.cacheControl(code); cc != "" {
+		h.Set("Cache-Control", cc)
+	}
+}
+
+// hasValue checks if a header value is already set so that setting error headers twice is harmless
+func hasValue(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl resolves the Cache-Control header of an error response
+func (rs *Responder) cacheControl(code int) string {
+	if rs.CacheControl != nil {
+		return rs.CacheControl(code)
+	}
+	return "no-store"
+}
+
+// statusCode resolves the status code of an error using DefaultStatusCode for errors without one
+func (rs *Responder) statusCode(err error) int {
+	var coder StatusCoder
+	if rs.DefaultStatusCode != 0 && !errors.As(err, &coder) {
+		return rs.DefaultStatusCode
+	}
+	return StatusCode(err)
+}
+
+// validUTF8 replaces invalid UTF-8 bytes in s with the Unicode replacement character
+func validUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// truncate shortens s to at most n bytes without splitting runes and adds an ellipsis if it was too long
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// verbosity resolves the verbosity of a response
+func (rs *Responder) verbosity() Verbosity {
+	if rs.Verbosity != Sampled {
+		return rs.Verbosity
+	}
+	random := rand.Float64
+	if rs.Rand != nil {
+		random = rs.Rand
+	}
+	if random() < rs.SampleRate {
+		return Verbose
+	}
+	return Minimal
+}
+
+func (rs *Responder) now() time.Time {
+	if rs.Now != nil {
+		return rs.Now()
+	}
+	return time.Now()
+}
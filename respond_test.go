@@ -0,0 +1,463 @@
+package httperr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestResponderSuccess(t *testing.T) {
+	rs := &Responder{Success: true}
+	for _, tc := range []struct {
+		name string
+		x    interface{}
+		code int
+		want string
+	}{
+		{"error", NotFound(errors.New("No such user")), http.StatusNotFound,
+			`{"success":false,"error":{"message":"No such user","error":"Not Found","statusCode":404}}` + "\n"},
+		{"data", map[string]int{"id": 42}, http.StatusOK,
+			`{"success":true,"data":{"id":42}}` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			if err := rs.RespondJSON(rr, tc.x); err != nil {
+				t.Fatal(err)
+			}
+			if rr.Code != tc.code {
+				t.Errorf("Invalid status code %d, want %d", rr.Code, tc.code)
+			}
+			if body := rr.Body.String(); body != tc.want {
+				t.Errorf("Invalid body %q, want %q", body, tc.want)
+			}
+		})
+	}
+}
+
+func TestRespondJSONNil(t *testing.T) {
+	rr := httptest.NewRecorder()
+	if err := RespondJSON(rr, nil); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusOK)
+	}
+	if body := rr.Body.String(); body != "null\n" {
+		t.Errorf("Invalid body %q, want %q", body, "null\n")
+	}
+}
+
+func TestRespondJSONContentLength(t *testing.T) {
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, BadRequest(errors.New("Missing name")))
+	want := strconv.Itoa(rr.Body.Len())
+	if got := rr.Header().Get("Content-Length"); got != want {
+		t.Errorf("Invalid Content-Length %q, want %q", got, want)
+	}
+	rr = httptest.NewRecorder()
+	RespondJSON(rr, New(http.StatusNotModified, nil))
+	if got := rr.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Invalid Content-Length %q for a bodyless status", got)
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("Invalid body %q for a bodyless status", rr.Body.String())
+	}
+}
+
+func TestRespondRaw(t *testing.T) {
+	const body = `{"message":"No such user","error":"Not Found","statusCode":404}`
+	rr := httptest.NewRecorder()
+	if err := RespondRaw(rr, http.StatusNotFound, "application/json", strings.NewReader(body)); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Invalid content type %q", got)
+	}
+	if got := rr.Body.String(); got != body {
+		t.Errorf("Invalid body %q, want %q", got, body)
+	}
+	rr = httptest.NewRecorder()
+	RespondRaw(rr, http.StatusNoContent, "application/json", strings.NewReader(body))
+	if rr.Body.Len() != 0 {
+		t.Errorf("Invalid body %q for a bodyless status", rr.Body.String())
+	}
+}
+
+// bodyKeys returns the sorted top level keys of a JSON object
+func bodyKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("Failed to decode body %q: %s", data, err)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestResponderVerbosity(t *testing.T) {
+	err := WithField(NewCaller(http.StatusBadRequest, errors.New("Missing name")), "user", 42)
+	for _, tc := range []struct {
+		verbosity Verbosity
+		message   string
+		keys      string
+	}{
+		{Minimal, "Bad Request", "error message statusCode"},
+		{Standard, "Missing name", "error message statusCode"},
+		{Verbose, "Missing name", "error fields message stack statusCode"},
+	} {
+		rr := httptest.NewRecorder()
+		(&Responder{Verbosity: tc.verbosity}).RespondJSON(rr, err)
+		if keys := strings.Join(bodyKeys(t, rr.Body.Bytes()), " "); keys != tc.keys {
+			t.Errorf("Invalid keys %q for verbosity %d, want %q", keys, tc.verbosity, tc.keys)
+		}
+		var resp Response
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Message != tc.message {
+			t.Errorf("Invalid message %q for verbosity %d, want %q", resp.Message, tc.verbosity, tc.message)
+		}
+	}
+}
+
+func TestRespondResponse(t *testing.T) {
+	for _, tc := range []struct {
+		resp *Response
+		want int
+	}{
+		{&Response{Message: "Slow down", StatusCode: http.StatusTooManyRequests}, http.StatusTooManyRequests},
+		{&Response{Message: "Oops"}, http.StatusInternalServerError},
+	} {
+		rr := httptest.NewRecorder()
+		if err := RespondResponse(rr, tc.resp); err != nil {
+			t.Fatal(err)
+		}
+		if rr.Code != tc.want {
+			t.Errorf("Invalid status code %d, want %d", rr.Code, tc.want)
+		}
+		var body Response
+		json.Unmarshal(rr.Body.Bytes(), &body)
+		if body.StatusCode != tc.want || body.Message != tc.resp.Message {
+			t.Errorf("Invalid body %s", rr.Body.Bytes())
+		}
+	}
+}
+
+func TestResponderHeaders(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		rs      *Responder
+		nosniff string
+		frame   string
+	}{
+		{"default", &Responder{}, "nosniff", ""},
+		{"custom", &Responder{Headers: http.Header{"X-Frame-Options": {"DENY"}}}, "", "DENY"},
+		{"disabled", &Responder{Headers: http.Header{}}, "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, NotFound(nil))
+			if got := rr.Header().Get("X-Content-Type-Options"); got != tc.nosniff {
+				t.Errorf("Invalid X-Content-Type-Options %q, want %q", got, tc.nosniff)
+			}
+			if got := rr.Header().Get("X-Frame-Options"); got != tc.frame {
+				t.Errorf("Invalid X-Frame-Options %q, want %q", got, tc.frame)
+			}
+		})
+	}
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, map[string]string{"ok": "yes"})
+	if got := rr.Header().Get("X-Content-Type-Options"); got != "" {
+		t.Errorf("Invalid X-Content-Type-Options %q on a success response", got)
+	}
+}
+
+func TestResponderDisableHTMLEscape(t *testing.T) {
+	err := BadRequest(errors.New("Invalid <tag> & more"))
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		want string
+	}{
+		{"default", &Responder{}, `"message":"Invalid \u003ctag\u003e \u0026 more"`},
+		{"disabled", &Responder{DisableHTMLEscape: true}, `"message":"Invalid <tag> & more"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, err)
+			if body := rr.Body.String(); !strings.Contains(body, tc.want) {
+				t.Errorf("Invalid body %s, want it to contain %s", body, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewResponder(t *testing.T) {
+	logger := func(r *http.Request, err error, bytes int, dur time.Duration) {}
+	rs := NewResponder(
+		WithLogger(logger),
+		WithVerbosity(Verbose),
+		WithDefaultStatusCode(http.StatusBadGateway),
+	)
+	if rs.Logger == nil {
+		t.Error("Logger was not set")
+	}
+	if rs.Verbosity != Verbose {
+		t.Errorf("Invalid verbosity %d, want %d", rs.Verbosity, Verbose)
+	}
+	if rs.DefaultStatusCode != http.StatusBadGateway {
+		t.Errorf("Invalid default status code %d, want %d", rs.DefaultStatusCode, http.StatusBadGateway)
+	}
+	rr := httptest.NewRecorder()
+	rs.RespondJSON(rr, errors.New("Plain error"))
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusBadGateway)
+	}
+	if rs := NewResponder(); rs.Verbosity != Standard || rs.DefaultStatusCode != 0 {
+		t.Errorf("Invalid zero options Responder %+v", rs)
+	}
+}
+
+// unmarshalableError fails to encode as JSON
+type unmarshalableError struct{}
+
+func (unmarshalableError) Error() string {
+	return "Unmarshalable"
+}
+
+func (unmarshalableError) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("Marshal failed")
+}
+
+func TestRespondJSONFallback(t *testing.T) {
+	rr := httptest.NewRecorder()
+	if err := RespondJSON(rr, unmarshalableError{}); err == nil {
+		t.Error("Expected the encoding error")
+	}
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if got := rr.Body.String(); got != string(fallbackBody) {
+		t.Errorf("Invalid body %q, want %q", got, fallbackBody)
+	}
+
+	var fallbackErr error
+	rs := &Responder{Fallback: func(w http.ResponseWriter, err error) error {
+		fallbackErr = err
+		w.WriteHeader(http.StatusTeapot)
+		return nil
+	}}
+	rr = httptest.NewRecorder()
+	rs.RespondJSON(rr, unmarshalableError{})
+	if fallbackErr == nil || rr.Code != http.StatusTeapot {
+		t.Errorf("Fallback was not called: %v %d", fallbackErr, rr.Code)
+	}
+}
+
+func TestResponderSampleRate(t *testing.T) {
+	err := WithField(BadRequest(errors.New("Missing name")), "user", 42)
+	for _, tc := range []struct {
+		rate    float64
+		message string
+		fields  bool
+	}{
+		{0, "Bad Request", false},
+		{1, "Missing name", true},
+	} {
+		rs := &Responder{Verbosity: Sampled, SampleRate: tc.rate, Rand: func() float64 { return 0.5 }}
+		for i := 0; i < 10; i++ {
+			resp := rs.response(context.Background(), http.StatusBadRequest, err)
+			if resp.Message != tc.message || (resp.Fields != nil) != tc.fields {
+				t.Fatalf("Invalid response %+v for sample rate %v", resp, tc.rate)
+			}
+		}
+	}
+}
+
+func TestRespondJSONInvalidUTF8(t *testing.T) {
+	rr := httptest.NewRecorder()
+	RespondJSON(rr, BadRequest(errors.New("Invalid name \xff\xfe")))
+	if !utf8.Valid(rr.Body.Bytes()) {
+		t.Errorf("Body %q is not valid UTF-8", rr.Body.Bytes())
+	}
+	var resp Response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Invalid name ��"; resp.Message != want {
+		t.Errorf("Invalid message %q, want %q", resp.Message, want)
+	}
+}
+
+func TestResponderOmitErrorField(t *testing.T) {
+	err := WithExtension(BadRequest(errors.New("Missing <name>")), "code", "E42")
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		want string
+	}{
+		{"default", &Responder{}, `{"message":"Missing \u003cname\u003e","error":"Bad Request","statusCode":400,"code":"E42"}`},
+		{"omitted", &Responder{OmitErrorField: true}, `{"message":"Missing \u003cname\u003e","statusCode":400,"code":"E42"}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, err)
+			if got := strings.TrimSpace(rr.Body.String()); got != tc.want {
+				t.Errorf("Invalid body %s, want %s", got, tc.want)
+			}
+		})
+	}
+	data, _ := json.Marshal(&Response{Message: "Custom", StatusCode: http.StatusTeapot})
+	if keys := strings.Join(bodyKeys(t, data), " "); keys != "error message statusCode" {
+		t.Errorf("Invalid default keys %q", keys)
+	}
+}
+
+func TestResponderOmitStatusCodeField(t *testing.T) {
+	err := NotFound(nil)
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		keys string
+	}{
+		{"default", &Responder{}, "error message statusCode"},
+		{"omitted", &Responder{OmitStatusCodeField: true}, "error message"},
+		{"both", &Responder{OmitStatusCodeField: true, OmitErrorField: true}, "message"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, err)
+			if keys := strings.Join(bodyKeys(t, rr.Body.Bytes()), " "); keys != tc.keys {
+				t.Errorf("Invalid keys %q, want %q", keys, tc.keys)
+			}
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+			}
+		})
+	}
+	data, _ := json.Marshal(&Response{Message: "Custom"})
+	if keys := strings.Join(bodyKeys(t, data), " "); keys != "error message statusCode" {
+		t.Errorf("Invalid keys %q for a zero status code", keys)
+	}
+}
+
+func TestResponderIndent(t *testing.T) {
+	rr := httptest.NewRecorder()
+	(&Responder{Indent: "  "}).RespondJSON(rr, NotFound(nil))
+	want := "{\n  \"message\": \"Not Found\",\n  \"error\": \"Not Found\",\n  \"statusCode\": 404\n}\n"
+	if got := rr.Body.String(); got != want {
+		t.Errorf("Invalid body %q, want %q", got, want)
+	}
+	rr = httptest.NewRecorder()
+	RespondJSON(rr, NotFound(nil))
+	if lines := strings.Count(rr.Body.String(), "\n"); lines != 1 {
+		t.Errorf("Invalid compact body %q", rr.Body.String())
+	}
+}
+
+func TestRespondJSONObject(t *testing.T) {
+	obj := map[string]interface{}{"requestId": "abc", "data": nil}
+	rr := httptest.NewRecorder()
+	RespondJSONObject(rr, obj, NotFound(errors.New("No such user")))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	want := `{"data":null,"error":{"message":"No such user","error":"Not Found","statusCode":404},"requestId":"abc"}` + "\n"
+	if got := rr.Body.String(); got != want {
+		t.Errorf("Invalid body %s, want %s", got, want)
+	}
+	if _, ok := obj["error"]; ok {
+		t.Error("Object was modified")
+	}
+	rr = httptest.NewRecorder()
+	RespondJSONObject(rr, obj, nil)
+	if got := rr.Body.String(); rr.Code != http.StatusOK || got != `{"data":null,"requestId":"abc"}`+"\n" {
+		t.Errorf("Invalid success response %d %s", rr.Code, got)
+	}
+}
+
+func TestResponderMaxMessageLength(t *testing.T) {
+	for _, tc := range []struct {
+		max     int
+		message string
+		want    string
+	}{
+		{0, "No such user", "No such user"},
+		{12, "No such user", "No such user"},
+		{5, "No such user", "No su..."},
+		{3, "Δεν", "Δ..."},
+	} {
+		rs := &Responder{MaxMessageLength: tc.max}
+		rr := httptest.NewRecorder()
+		rs.RespondJSON(rr, NotFound(errors.New(tc.message)))
+		var resp Response
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Message != tc.want {
+			t.Errorf("Invalid message %q with limit %d, want %q", resp.Message, tc.max, tc.want)
+		}
+	}
+}
+
+func TestResponderCacheControl(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rs   *Responder
+		err  error
+		want string
+	}{
+		{"default", &Responder{}, NotFound(nil), "no-store"},
+		{"success", &Responder{}, New(http.StatusOK, nil), ""},
+		{"disabled", &Responder{CacheControl: func(int) string { return "" }}, NotFound(nil), ""},
+		{"per status", &Responder{CacheControl: func(code int) string {
+			if code == http.StatusGone {
+				return "max-age=3600"
+			}
+			return "no-store"
+		}}, New(http.StatusGone, nil), "max-age=3600"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.rs.RespondJSON(rr, tc.err)
+			if got := rr.Header().Get("Cache-Control"); got != tc.want {
+				t.Errorf("Invalid Cache-Control %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRespondPrebuiltHeaders(t *testing.T) {
+	rr := httptest.NewRecorder()
+	RespondResponse(rr, &Response{Message: "Oops"})
+	if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Invalid Cache-Control %q for RespondResponse", got)
+	}
+	if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("Invalid X-Content-Type-Options %q for RespondResponse", got)
+	}
+	rr = httptest.NewRecorder()
+	RespondRaw(rr, http.StatusNotFound, "text/plain", strings.NewReader("Not Found"))
+	if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Invalid Cache-Control %q for RespondRaw", got)
+	}
+	rr = httptest.NewRecorder()
+	RespondRaw(rr, http.StatusOK, "text/plain", strings.NewReader("OK"))
+	if got := rr.Header().Get("Cache-Control"); got != "" {
+		t.Errorf("Invalid Cache-Control %q for a success", got)
+	}
+}
@@ -0,0 +1,319 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	errors "golang.org/x/xerrors"
+)
+
+// Response is a response message
+type Response struct {
+	Message    string `json:"message"`
+	Error      string `json:"error"`
+	StatusCode int    `json:"statusCode"`
+	TraceID    string `json:"traceId,omitempty"`
+	TimeoutMs  int64  `json:"timeoutMs,omitempty"`
+	// Type is a URI identifying the error type, see WithType
+	Type string `json:"type,omitempty"`
+	// Validation holds the messages of a ValidationError by field
+	Validation map[string]string `json:"validation,omitempty"`
+	// Fields and Stack are only included in verbose bodies
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	Stack  []string               `json:"stack,omitempty"`
+	// Extensions are additional members of the response object
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// responseKeys are the JSON keys of the standard Response fields
+var responseKeys = jsonKeys(Response{})
+
+// jsonKeys returns the JSON keys of the fields of a struct
+func jsonKeys(x interface{}) map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(x)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// MarshalJSON implements json.Marshaler.
+// Extension members follow the standard fields in sorted key order
+// so that the output is deterministic.
+// Extensions clashing with standard fields are ignored.
+// HTML characters are left unescaped for the calling encoder to handle.
+func (r Response) MarshalJSON() ([]byte, error) {
+	type response Response
+	data, err := marshalJSON(response(r))
+	if err != nil || len(r.Extensions) == 0 {
+		return data, err
+	}
+	return appendMembers(data, r.Extensions, responseKeys)
+}
+
+// appendMembers adds members to an encoded JSON object in sorted key order skipping reserved keys
+func appendMembers(data []byte, members map[string]interface{}, reserved map[string]bool) ([]byte, error) {
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		if !reserved[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for _, k := range keys {
+		key, _ := marshalJSON(k)
+		value, err := marshalJSON(members[k])
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// omitMembers encodes a Response without some of its standard members
+type omitMembers struct {
+	resp *Response
+	keys map[string]bool
+}
+
+// MarshalJSON implements json.Marshaler
+func (o *omitMembers) MarshalJSON() ([]byte, error) {
+	data, err := o.resp.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return removeMembers(data, o.keys)
+}
+
+// removeMembers removes members from an encoded JSON object keeping the order of the rest
+func removeMembers(data []byte, keys map[string]bool) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString("{")
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		if keys[key] {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := marshalJSON(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalJSON encodes x as JSON without escaping HTML characters
+func marshalJSON(x interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(x); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The status code can be either a JSON number or a numeric string.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	type response Response
+	var tmp struct {
+		*response
+		StatusCode json.RawMessage `json:"statusCode"`
+	}
+	tmp.response = (*response)(r)
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	code := string(bytes.Trim(tmp.StatusCode, `"`))
+	if code == "" || code == "null" {
+		r.StatusCode = 0
+		return nil
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return errors.Errorf("Invalid status code %s", tmp.StatusCode)
+	}
+	r.StatusCode = n
+	return nil
+}
+
+// ToMap converts r to a map with the same members as its JSON object.
+// Empty optional fields are omitted and extensions clashing with standard fields are ignored.
+func (r *Response) ToMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Extensions)+8)
+	for k, v := range r.Extensions {
+		if !responseKeys[k] {
+			m[k] = v
+		}
+	}
+	m["message"] = r.Message
+	m["error"] = r.Error
+	m["statusCode"] = r.StatusCode
+	if r.TraceID != "" {
+		m["traceId"] = r.TraceID
+	}
+	if r.TimeoutMs != 0 {
+		m["timeoutMs"] = r.TimeoutMs
+	}
+	if r.Type != "" {
+		m["type"] = r.Type
+	}
+	if len(r.Validation) > 0 {
+		m["validation"] = r.Validation
+	}
+	if len(r.Fields) > 0 {
+		m["fields"] = r.Fields
+	}
+	if len(r.Stack) > 0 {
+		m["stack"] = r.Stack
+	}
+	return m
+}
+
+// FromMap creates a Response from a map in the form returned by ToMap or decoded from JSON.
+// Missing members and members of the wrong type are left empty.
+// Unknown members are kept as extensions.
+func FromMap(m map[string]interface{}) *Response {
+	r := &Response{}
+	for k, v := range m {
+		switch k {
+		case "message":
+			r.Message, _ = v.(string)
+		case "error":
+			r.Error, _ = v.(string)
+		case "statusCode":
+			r.StatusCode = int(toInt64(v))
+		case "traceId":
+			r.TraceID, _ = v.(string)
+		case "timeoutMs":
+			r.TimeoutMs = toInt64(v)
+		case "type":
+			r.Type, _ = v.(string)
+		case "validation":
+			r.Validation = toStringMap(v)
+		case "fields":
+			r.Fields, _ = v.(map[string]interface{})
+		case "stack":
+			r.Stack = toStrings(v)
+		default:
+			if r.Extensions == nil {
+				r.Extensions = make(map[string]interface{})
+			}
+			r.Extensions[k] = v
+		}
+	}
+	return r
+}
+
+// toInt64 converts integers, JSON numbers and numeric strings to int64
+func toInt64(v interface{}) int64 {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	default:
+		return 0
+	}
+}
+
+// toStringMap converts a map of strings keeping only string values
+func toStringMap(v interface{}) map[string]string {
+	switch v := v.(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		m := make(map[string]string, len(v))
+		for k, v := range v {
+			if s, ok := v.(string); ok {
+				m[k] = s
+			}
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+// toStrings converts a slice of strings keeping only string values
+func toStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, v := range v {
+			if v, ok := v.(string); ok {
+				s = append(s, v)
+			}
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+// NewResponse creates the Response message for an error
+func NewResponse(err error) *Response {
+	return defaultResponder.response(context.Background(), StatusCode(err), err)
+}
+
+func (e *httpError) response() Response {
+	resp := Response{
+		Message:    e.Message(),
+		Error:      e.statusText(),
+		StatusCode: e.code,
+	}
+	var v ValidationError
+	if errors.As(e.err, &v) {
+		resp.Validation = v
+	}
+	resp.Type = Type(e)
+	resp.Extensions = Extensions(e)
+	return resp
+}
+
+func (e *httpError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.response())
+}
@@ -0,0 +1,84 @@
+package httperr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestResponseDeterministic(t *testing.T) {
+	err := UnprocessableEntity(ValidationError{"name": "Required", "email": "Invalid", "age": "Too low"})
+	for _, k := range []string{"zeta", "alpha", "mid", "beta", "omega"} {
+		err = WithExtension(err, k, k)
+		err = WithField(err, k, k)
+	}
+	rs := &Responder{Verbosity: Verbose}
+	marshal := func() []byte {
+		data, err := json.Marshal(rs.response(context.Background(), http.StatusUnprocessableEntity, err))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	want := marshal()
+	for i := 0; i < 20; i++ {
+		if got := marshal(); !bytes.Equal(got, want) {
+			t.Fatalf("Marshal is not deterministic:\n%s\n%s", got, want)
+		}
+	}
+}
+
+func TestResponseUnmarshalStatusCode(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want int
+	}{
+		{`{"message":"Gone","statusCode":404}`, 404},
+		{`{"message":"Gone","statusCode":"404"}`, 404},
+		{`{"message":"Gone"}`, 0},
+		{`{"message":"Gone","statusCode":null}`, 0},
+	} {
+		var r Response
+		if err := json.Unmarshal([]byte(tc.data), &r); err != nil {
+			t.Errorf("Failed to unmarshal %s: %s", tc.data, err)
+			continue
+		}
+		if r.StatusCode != tc.want || r.Message != "Gone" {
+			t.Errorf("Invalid response %+v for %s, want status code %d", r, tc.data, tc.want)
+		}
+	}
+	var r Response
+	if err := json.Unmarshal([]byte(`{"statusCode":"Gone"}`), &r); err == nil {
+		t.Error("Expected an error for a non numeric status code")
+	}
+}
+
+func TestResponseMapRoundTrip(t *testing.T) {
+	resp := &Response{
+		Message:    "Invalid input",
+		Error:      "Unprocessable Entity",
+		StatusCode: http.StatusUnprocessableEntity,
+		TraceID:    "abc",
+		Type:       "https://example.com/invalid",
+		Validation: map[string]string{"name": "Required"},
+		Stack:      []string{"main.go:42"},
+		Extensions: map[string]interface{}{"code": "E42"},
+	}
+	if got := FromMap(resp.ToMap()); !reflect.DeepEqual(got, resp) {
+		t.Errorf("Invalid round trip %+v, want %+v", got, resp)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if got := FromMap(m); !reflect.DeepEqual(got, resp) {
+		t.Errorf("Invalid JSON round trip %+v, want %+v", got, resp)
+	}
+}
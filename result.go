@@ -0,0 +1,70 @@
+package httperr
+
+// ResultBuilder collects the payload of a partially successful request along with non-fatal warnings.
+// RespondJSON sends it as an HTTP 200 body with "data" and "warnings" fields
+// or as an error response if a fatal error was added.
+// A ResultBuilder is not safe for concurrent use.
+type ResultBuilder struct {
+	data     interface{}
+	warnings []string
+	err      error
+}
+
+// NewResult creates a ResultBuilder with a success payload
+func NewResult(data interface{}) *ResultBuilder {
+	return &ResultBuilder{data: data}
+}
+
+// SetData sets the success payload
+func (b *ResultBuilder) SetData(data interface{}) *ResultBuilder {
+	b.data = data
+	return b
+}
+
+// Warn adds the message of a non-fatal error as a warning. Nil errors are ignored.
+func (b *ResultBuilder) Warn(err error) *ResultBuilder {
+	if err != nil {
+		b.warnings = append(b.warnings, Message(err))
+	}
+	return b
+}
+
+// Fail adds a fatal error that turns the result into an error response.
+// Only the first fatal error is kept and nil errors are ignored.
+func (b *ResultBuilder) Fail(err error) *ResultBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// Warnings returns the warning messages added so far
+func (b *ResultBuilder) Warnings() []string {
+	return b.warnings
+}
+
+// Err returns the fatal error with the warnings added as a "warnings" extension or nil if there is none
+func (b *ResultBuilder) Err() error {
+	if b.err == nil || len(b.warnings) == 0 {
+		return b.err
+	}
+	return WithExtension(b.err, "warnings", b.warnings)
+}
+
+// resultBody is the JSON body of a successful result
+type resultBody struct {
+	Data     interface{} `json:"data"`
+	Warnings []string    `json:"warnings"`
+}
+
+// result returns the value to respond with for a result
+func (b *ResultBuilder) result() interface{} {
+	if err := b.Err(); err != nil {
+		return err
+	}
+	warnings := b.warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return resultBody{Data: b.data, Warnings: warnings}
+}
@@ -0,0 +1,36 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestResultBuilder(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		b    *ResultBuilder
+		code int
+		want string
+	}{
+		{"empty", NewResult([]int{1}), http.StatusOK,
+			`{"data":[1],"warnings":[]}` + "\n"},
+		{"warnings", NewResult([]int{1}).Warn(NotFound(errors.New("Item 2 is gone"))).Warn(nil).Warn(errors.New("Item 3 is stale")), http.StatusOK,
+			`{"data":[1],"warnings":["Item 2 is gone","Item 3 is stale"]}` + "\n"},
+		{"fatal", NewResult(nil).Warn(errors.New("Item 3 is stale")).Fail(ServiceUnavailable(errors.New("Store is down"))).Fail(BadRequest(nil)), http.StatusServiceUnavailable,
+			`{"message":"Store is down","error":"Service Unavailable","statusCode":503,"warnings":["Item 3 is stale"]}` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			RespondJSON(rr, tc.b)
+			if rr.Code != tc.code {
+				t.Errorf("Invalid status code %d, want %d", rr.Code, tc.code)
+			}
+			if got := rr.Body.String(); got != tc.want {
+				t.Errorf("Invalid body %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
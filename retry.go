@@ -0,0 +1,143 @@
+package httperr
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+// IsRetryable checks if err has a status code that indicates the request can be retried.
+// These are 408, 429, 502, 503 and 504.
+func IsRetryable(err error) bool {
+	switch StatusCode(err) {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// SafeToRetry checks if a request that failed with err can be safely retried.
+// The method must be idempotent (GET, HEAD, PUT, DELETE or OPTIONS) and err retryable.
+func SafeToRetry(method string, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return IsRetryable(err)
+	}
+	return false
+}
+
+// IsTransient checks if err is a temporary condition that may clear up on its own.
+// Errors are transient if they have a 429, 503 or 504 status code
+// or a net.Error timeout in their chain.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch StatusCode(err) {
+	case http.StatusTooManyRequests,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsPermanent checks if err is not transient and will not clear up by repeating the request.
+// It returns false for nil errors.
+func IsPermanent(err error) bool {
+	return err != nil && !IsTransient(err)
+}
+
+// TripStatus classifies the status codes for which ShouldTrip returns true.
+// It defaults to IsServerError and can be replaced during initialization.
+var TripStatus = IsServerError
+
+// ShouldTrip checks if err is a fault of the remote end that should trip a circuit breaker.
+// Transport errors and errors with a status code matching TripStatus trip while
+// client errors, canceled requests and nil do not.
+func ShouldTrip(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return TripStatus(StatusCode(err))
+}
+
+// RetryTransport wraps rt to retry requests that fail with a retryable error up to max times.
+// Before retry n, starting from 1, it waits for backoff(n) unless the request context is done.
+// Only idempotent requests whose body can be rewound with GetBody are retried,
+// an empty method counts as GET like in http.Client.
+// If max is zero or less no request is retried and responses are returned unchanged.
+// If all retries fail, the error of the last attempt is returned, created with FromResponse
+// for error responses and FromClientError for transport failures.
+// A nil rt uses http.DefaultTransport.
+func RetryTransport(rt http.RoundTripper, max int, backoff func(attempt int) time.Duration) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &retryTransport{rt: rt, max: max, backoff: backoff}
+}
+
+type retryTransport struct {
+	rt      http.RoundTripper
+	max     int
+	backoff func(attempt int) time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.max <= 0 {
+		return t.rt.RoundTrip(req)
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.rt.RoundTrip(req)
+		var failed error
+		if err != nil {
+			failed = FromClientError(err)
+		} else {
+			failed = New(resp.StatusCode, nil)
+		}
+		if !SafeToRetry(method, failed) || !rewindable(req) {
+			return resp, err
+		}
+		if resp != nil {
+			failed = FromResponse(resp)
+		}
+		if attempt == t.max {
+			return nil, failed
+		}
+		if t.backoff != nil {
+			timer := time.NewTimer(t.backoff(attempt + 1))
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, failed
+			}
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, failed
+			}
+			retry := *req
+			retry.Body = body
+			req = &retry
+		}
+	}
+}
+
+// rewindable checks if the body of a request can be sent again
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
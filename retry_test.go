@@ -0,0 +1,176 @@
+package httperr
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestSafeToRetry(t *testing.T) {
+	methods := map[string]bool{
+		http.MethodGet:     true,
+		http.MethodHead:    true,
+		http.MethodPut:     true,
+		http.MethodDelete:  true,
+		http.MethodOptions: true,
+		http.MethodPost:    false,
+		http.MethodPatch:   false,
+	}
+	codes := map[int]bool{
+		http.StatusRequestTimeout:      true,
+		http.StatusTooManyRequests:     true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+		http.StatusBadRequest:          false,
+		http.StatusNotFound:            false,
+		http.StatusInternalServerError: false,
+	}
+	for method, idempotent := range methods {
+		for code, retryable := range codes {
+			want := idempotent && retryable
+			if got := SafeToRetry(method, New(code, nil)); got != want {
+				t.Errorf("SafeToRetry(%s, %d) = %t, want %t", method, code, got, want)
+			}
+		}
+	}
+}
+
+// roundTripFunc is an http.RoundTripper function
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// flakyTransport responds with 503 to the first request and 200 afterwards
+func flakyTransport(calls *int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*calls++
+		code := http.StatusOK
+		if *calls == 1 {
+			code = http.StatusServiceUnavailable
+		}
+		return &http.Response{
+			StatusCode: code,
+			Status:     strconv.Itoa(code) + " " + http.StatusText(code),
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       ioutil.NopCloser(strings.NewReader(http.StatusText(code))),
+			Request:    req,
+		}, nil
+	})
+}
+
+func TestRetryTransport(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		method string
+		max    int
+		code   int
+		calls  int
+	}{
+		{"retried", http.MethodGet, 2, http.StatusOK, 2},
+		{"empty method", "", 2, http.StatusOK, 2},
+		{"not idempotent", http.MethodPost, 2, http.StatusServiceUnavailable, 1},
+		{"no retries", http.MethodGet, 0, http.StatusServiceUnavailable, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			var backoffs []int
+			rt := RetryTransport(flakyTransport(&calls), tc.max, func(attempt int) time.Duration {
+				backoffs = append(backoffs, attempt)
+				return time.Millisecond
+			})
+			req := httptest.NewRequest(tc.method, "http://example.com/", nil)
+			req.Method = tc.method
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.code || calls != tc.calls {
+				t.Errorf("Invalid status code %d after %d calls, want %d after %d", resp.StatusCode, calls, tc.code, tc.calls)
+			}
+			if body, _ := ioutil.ReadAll(resp.Body); string(body) != http.StatusText(tc.code) {
+				t.Errorf("Invalid body %q", body)
+			}
+			if len(backoffs) != tc.calls-1 {
+				t.Errorf("Invalid backoff attempts %v", backoffs)
+			}
+		})
+	}
+}
+
+func TestRetryTransportExhausted(t *testing.T) {
+	var calls int
+	rt := RetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       ioutil.NopCloser(strings.NewReader("Down for maintenance")),
+		}, nil
+	}), 2, nil)
+	resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	if resp != nil || StatusCode(err) != http.StatusServiceUnavailable || Message(err) != "Down for maintenance" {
+		t.Errorf("Invalid result %v, %v", resp, err)
+	}
+	if calls != 3 {
+		t.Errorf("Invalid number of calls %d, want 3", calls)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"429", New(http.StatusTooManyRequests, nil), true},
+		{"503", New(http.StatusServiceUnavailable, nil), true},
+		{"504", New(http.StatusGatewayTimeout, nil), true},
+		{"net timeout", &net.OpError{Op: "read", Err: timeoutError{}}, true},
+		{"wrapped net timeout", New(http.StatusBadGateway, timeoutError{}), true},
+		{"400", New(http.StatusBadRequest, nil), false},
+		{"404", New(http.StatusNotFound, nil), false},
+		{"500", New(http.StatusInternalServerError, nil), false},
+		{"plain", errors.New("Plain error"), false},
+	} {
+		if got := IsTransient(tc.err); got != tc.transient {
+			t.Errorf("%s: IsTransient = %t, want %t", tc.name, got, tc.transient)
+		}
+		if got := IsPermanent(tc.err); got == tc.transient {
+			t.Errorf("%s: IsPermanent = %t, want %t", tc.name, got, !tc.transient)
+		}
+	}
+	if IsTransient(nil) || IsPermanent(nil) {
+		t.Error("Nil is transient or permanent")
+	}
+}
+
+func TestShouldTrip(t *testing.T) {
+	for _, code := range []int{400, 401, 404, 409, 422, 429} {
+		if ShouldTrip(New(code, nil)) {
+			t.Errorf("HTTP %d trips", code)
+		}
+	}
+	for _, code := range []int{500, 502, 503, 504} {
+		if !ShouldTrip(New(code, nil)) {
+			t.Errorf("HTTP %d does not trip", code)
+		}
+	}
+	if !ShouldTrip(errors.New("Connection refused")) {
+		t.Error("Transport error does not trip")
+	}
+	if ShouldTrip(nil) || ShouldTrip(FromClientError(context.Canceled)) {
+		t.Error("Nil or canceled request trips")
+	}
+}
@@ -0,0 +1,32 @@
+//go:build go1.21
+// +build go1.21
+
+package httperr
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// LogValue implements slog.LogValuer.
+// The error is logged as a group with status, message and fields attributes.
+func (e *httpError) LogValue() slog.Value {
+	resp := e.response()
+	attrs := []slog.Attr{
+		slog.Int("status", e.code),
+		slog.String("message", resp.Message),
+	}
+	if fields := Fields(e); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		group := make([]slog.Attr, 0, len(keys))
+		for _, k := range keys {
+			group = append(group, slog.Any(k, fields[k]))
+		}
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(group...)})
+	}
+	return slog.GroupValue(attrs...)
+}
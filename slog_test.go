@@ -0,0 +1,39 @@
+//go:build go1.21
+// +build go1.21
+
+package httperr
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestLogValue(t *testing.T) {
+	err := WithField(WithField(NotFound(errors.New("No such user")), "user", 42), "action", "load")
+	v := err.(slog.LogValuer).LogValue()
+	if v.Kind() != slog.KindGroup {
+		t.Fatalf("Invalid kind %s, want %s", v.Kind(), slog.KindGroup)
+	}
+	attrs := v.Group()
+	if len(attrs) != 3 {
+		t.Fatalf("Invalid attributes %v", attrs)
+	}
+	if a := attrs[0]; a.Key != "status" || a.Value.Int64() != http.StatusNotFound {
+		t.Errorf("Invalid status attribute %v", a)
+	}
+	if a := attrs[1]; a.Key != "message" || a.Value.String() != "No such user" {
+		t.Errorf("Invalid message attribute %v", a)
+	}
+	fields := attrs[2]
+	if fields.Key != "fields" || fields.Value.Kind() != slog.KindGroup {
+		t.Fatalf("Invalid fields attribute %v", fields)
+	}
+	group := fields.Value.Group()
+	if len(group) != 2 || group[0].Key != "action" || group[0].Value.String() != "load" ||
+		group[1].Key != "user" || group[1].Value.Int64() != 42 {
+		t.Errorf("Invalid fields %v, want sorted action and user", group)
+	}
+}
@@ -0,0 +1,53 @@
+package httperr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	errors "golang.org/x/xerrors"
+)
+
+// ParseStatus extracts the status code from a status string like "404 Not Found".
+// A leading HTTP version, as in "HTTP/1.1 404 Not Found", is skipped.
+// It fails if the string does not start with a status code in the 100-599 range.
+func ParseStatus(s string) (int, error) {
+	code, _, err := parseStatus(s)
+	return code, err
+}
+
+// FromStatusLine creates an HTTP error from a status string like "503 Service Unavailable".
+// A non-standard reason phrase is preserved.
+// Malformed status strings result in an HTTP 500 error.
+func FromStatusLine(s string) error {
+	code, phrase, err := parseStatus(s)
+	if err != nil {
+		return InternalServerError(err)
+	}
+	e := &httpError{code: code}
+	if phrase != http.StatusText(code) {
+		e.status = phrase
+	}
+	return e
+}
+
+// parseStatus splits a status string into its code and reason phrase
+func parseStatus(s string) (int, string, error) {
+	line := strings.TrimSpace(s)
+	if strings.HasPrefix(line, "HTTP/") {
+		i := strings.IndexByte(line, ' ')
+		if i == -1 {
+			return 0, "", errors.Errorf("Invalid status %q", s)
+		}
+		line = strings.TrimLeft(line[i:], " ")
+	}
+	codeText, phrase := line, ""
+	if i := strings.IndexAny(line, " \t"); i != -1 {
+		codeText, phrase = line[:i], strings.TrimSpace(line[i:])
+	}
+	code, err := strconv.Atoi(codeText)
+	if err != nil || len(codeText) != 3 || code < http.StatusContinue || code > 599 {
+		return 0, "", errors.Errorf("Invalid status %q", s)
+	}
+	return code, phrase, nil
+}
@@ -0,0 +1,51 @@
+package httperr
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		code int
+	}{
+		{"404 Not Found", 404},
+		{"HTTP/1.1 503 Service Unavailable", 503},
+		{"HTTP/2 200", 200},
+		{"  418\tI'm a teapot  ", 418},
+		{"520", 520},
+	} {
+		code, err := ParseStatus(tc.s)
+		if err != nil || code != tc.code {
+			t.Errorf("ParseStatus(%q) = %d, %v, want %d", tc.s, code, err, tc.code)
+		}
+	}
+	for _, s := range []string{"", "Not Found", "HTTP/1.1", "4040 Not Found", "600 Too High", "099 Low", "+40 Plus", "abc"} {
+		if code, err := ParseStatus(s); err == nil {
+			t.Errorf("ParseStatus(%q) = %d, want an error", s, code)
+		}
+	}
+}
+
+func TestFromStatusLine(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		code int
+		want string
+	}{
+		{"404 Not Found", http.StatusNotFound, "404 Not Found"},
+		{"HTTP/1.1 520 Origin Error", 520, "520 Origin Error"},
+		{"429 Slow Down", http.StatusTooManyRequests, "429 Slow Down"},
+		{"garbage", http.StatusInternalServerError, "500 Internal Server Error"},
+	} {
+		err := FromStatusLine(tc.s)
+		if code := StatusCode(err); code != tc.code {
+			t.Errorf("Invalid status code %d for %q, want %d", code, tc.s, tc.code)
+		}
+		if got := err.Error(); !strings.HasPrefix(got, tc.want) {
+			t.Errorf("Invalid error %q for %q, want prefix %q", got, tc.s, tc.want)
+		}
+	}
+}
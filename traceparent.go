@@ -0,0 +1,67 @@
+package httperr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// setTraceParent echoes the W3C Trace Context traceparent header of a request on the response
+// or generates a new one if it is missing or malformed
+func (rs *Responder) setTraceParent(w http.ResponseWriter, r *http.Request) {
+	if !rs.TraceParent {
+		return
+	}
+	tp := r.Header.Get("traceparent")
+	if !validTraceParent(tp) {
+		tp = newTraceParent()
+		if tp == "" {
+			return
+		}
+	}
+	w.Header().Set("traceparent", tp)
+}
+
+// validTraceParent checks the format of a version 00 traceparent header
+func validTraceParent(tp string) bool {
+	const size = len("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	if len(tp) != size || tp[2] != '-' || tp[35] != '-' || tp[52] != '-' {
+		return false
+	}
+	for i, part := range []string{tp[:2], tp[3:35], tp[36:52], tp[53:]} {
+		if !isLowerHex(part) || isZero(part) && (i == 1 || i == 2) {
+			return false
+		}
+	}
+	return tp[:2] == "00"
+}
+
+// isLowerHex checks if s only has lowercase hexadecimal digits
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero checks if s only has zero digits
+func isZero(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// newTraceParent generates a traceparent header with random trace and parent IDs.
+// It returns "" if no random bytes are available.
+func newTraceParent() string {
+	var id [24]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(id[:16]) + "-" + hex.EncodeToString(id[16:]) + "-00"
+}
@@ -0,0 +1,46 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestResponderTraceParent(t *testing.T) {
+	const traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	for _, tc := range []struct {
+		name        string
+		rs          *Responder
+		traceParent string
+		echo        bool
+	}{
+		{"echo", &Responder{TraceParent: true}, traceParent, true},
+		{"generate", &Responder{TraceParent: true}, "", false},
+		{"malformed", &Responder{TraceParent: true}, "00-invalid", false},
+		{"disabled", &Responder{}, traceParent, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.traceParent != "" {
+				r.Header.Set("traceparent", tc.traceParent)
+			}
+			rr := httptest.NewRecorder()
+			tc.rs.Respond(rr, r, NotFound(errors.New("No such user")))
+			got := rr.Header().Get("traceparent")
+			switch {
+			case tc.echo:
+				if got != tc.traceParent {
+					t.Errorf("Invalid traceparent %q, want %q", got, tc.traceParent)
+				}
+			case !tc.rs.TraceParent:
+				if got != "" {
+					t.Errorf("Unexpected traceparent %q", got)
+				}
+			case !validTraceParent(got) || got == tc.traceParent:
+				t.Errorf("Invalid generated traceparent %q", got)
+			}
+		})
+	}
+}
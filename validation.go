@@ -0,0 +1,25 @@
+package httperr
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ValidationError is an HTTP 422 error with a message for each invalid field.
+// The messages are added to the response body under "validation".
+type ValidationError map[string]string
+
+func (v ValidationError) Error() string {
+	fields := make([]string, 0, len(v))
+	for field := range v {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return "Invalid fields: " + strings.Join(fields, ", ")
+}
+
+// StatusCode implements StatusCoder
+func (v ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
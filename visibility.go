@@ -0,0 +1,46 @@
+package httperr
+
+import errors "golang.org/x/xerrors"
+
+// visibility controls whether an error message is shown to clients
+type visibility int
+
+const (
+	visibilityDefault visibility = iota
+	visibilityPublic
+	visibilityInternal
+)
+
+// AsPublic returns a copy of err whose message is shown in error bodies.
+// It overrides an AsInternal of a wrapped error.
+// It returns nil if err is nil.
+func AsPublic(err error) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	e.visibility = visibilityPublic
+	return e
+}
+
+// AsInternal returns a copy of err whose message is replaced by the status text in error bodies.
+// The full message is still available to loggers.
+// It returns nil if err is nil.
+func AsInternal(err error) error {
+	if err == nil {
+		return nil
+	}
+	e := with(err)
+	e.visibility = visibilityInternal
+	return e
+}
+
+// IsInternal checks if the outermost error in the chain marked with AsPublic or AsInternal is internal
+func IsInternal(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*httpError); ok && e.visibility != visibilityDefault {
+			return e.visibility == visibilityInternal
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package httperr
+
+import (
+	"net/http"
+	"testing"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestVisibility(t *testing.T) {
+	cause := errors.New("pq: connection refused")
+	for _, tc := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"default", InternalServerError(cause), "pq: connection refused"},
+		{"internal", AsInternal(InternalServerError(cause)), "Internal Server Error"},
+		{"public", AsPublic(InternalServerError(cause)), "pq: connection refused"},
+		{"public over internal", AsPublic(New(http.StatusBadGateway, AsInternal(InternalServerError(cause)))), "pq: connection refused"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NewResponse(tc.err).Message; got != tc.want {
+				t.Errorf("Invalid message %q, want %q", got, tc.want)
+			}
+			if got := Message(tc.err); got != "pq: connection refused" {
+				t.Errorf("Invalid log message %q, want the full message", got)
+			}
+		})
+	}
+}
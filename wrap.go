@@ -0,0 +1,49 @@
+package httperr
+
+import "fmt"
+
+// Wrap adds context to the message of err like Wrapf
+func Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, msg)
+}
+
+// Wrapf adds context to the message of err, ie "Loading user: not found".
+//
+// If err is an HTTP error the result is a copy of it with the same status code,
+// headers, cookies, fields, extensions and Retry-After, whose cause is wrapped with the message.
+// Like other builders, later changes to the copy do not affect err.
+// Other errors are wrapped in a new HTTP error with their resolved status code.
+// It returns nil if err is nil.
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, fmt.Sprintf(format, args...))
+}
+
+// wrap wraps the cause of a modifiable copy of err with a message
+func wrap(err error, msg string) error {
+	e := with(err)
+	e.err = &wrapError{msg: msg, err: e.err}
+	return e
+}
+
+// wrapError prefixes the message of an error with context
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string {
+	if e.err == nil {
+		return e.msg
+	}
+	return e.msg + ": " + Message(e.err)
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,39 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	errors "golang.org/x/xerrors"
+)
+
+func TestWrapfMetadata(t *testing.T) {
+	err := NotFound(errors.New("No such user"))
+	err = WithHeader(err, "X-Request-Id", "abc")
+	err = WithField(err, "userId", 42)
+	err = WithRetryAfter(err, time.Minute)
+	wrapped := Wrapf(err, "Loading user %d", 42)
+	if got := StatusCode(wrapped); got != http.StatusNotFound {
+		t.Errorf("Invalid status code %d, want %d", got, http.StatusNotFound)
+	}
+	if got := Message(wrapped); got != "Loading user 42: No such user" {
+		t.Errorf("Invalid message %q", got)
+	}
+	if got := Fields(wrapped)["userId"]; got != 42 {
+		t.Errorf("Invalid field %v, want 42", got)
+	}
+	if got := RetryAfter(wrapped); got != time.Minute {
+		t.Errorf("Invalid Retry-After %s, want %s", got, time.Minute)
+	}
+	rr := httptest.NewRecorder()
+	SetErrorHeaders(rr, wrapped)
+	if got := rr.Header().Get("X-Request-Id"); got != "abc" {
+		t.Errorf("Invalid header %q, want %q", got, "abc")
+	}
+	WithField(wrapped, "userId", 7)
+	if got := Fields(err)["userId"]; got != 42 {
+		t.Errorf("Wrapped error was modified: %v", got)
+	}
+}
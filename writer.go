@@ -0,0 +1,156 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+)
+
+// wrappedWriter is a response writer wrapping another one that implements
+// all the optional interfaces of http.ResponseWriter
+type wrappedWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.Pusher
+	io.ReaderFrom
+	unwrapper
+}
+
+// unwrapper is a response writer wrapping another one for http.ResponseController
+type unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// Optional interfaces of the writer a wrappedWriter wraps
+const (
+	flusher = 1 << iota
+	hijacker
+	pusher
+	readerFrom
+)
+
+// exposeWriter returns a view of w that only implements the optional interfaces
+// of the writer it wraps so that handlers sniffing for them are not misled
+func exposeWriter(w wrappedWriter) http.ResponseWriter {
+	var features int
+	inner := w.Unwrap()
+	if _, ok := inner.(http.Flusher); ok {
+		features |= flusher
+	}
+	if _, ok := inner.(http.Hijacker); ok {
+		features |= hijacker
+	}
+	if _, ok := inner.(http.Pusher); ok {
+		features |= pusher
+	}
+	if _, ok := inner.(io.ReaderFrom); ok {
+		features |= readerFrom
+	}
+	switch features {
+	case flusher:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+		}{w, w, w}
+	case hijacker:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+		}{w, w, w}
+	case pusher:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Pusher
+		}{w, w, w}
+	case readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			io.ReaderFrom
+		}{w, w, w}
+	case flusher | hijacker:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+		}{w, w, w, w}
+	case flusher | pusher:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Pusher
+		}{w, w, w, w}
+	case flusher | readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			io.ReaderFrom
+		}{w, w, w, w}
+	case hijacker | pusher:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w}
+	case hijacker | readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			io.ReaderFrom
+		}{w, w, w, w}
+	case pusher | readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w}
+	case flusher | hijacker | pusher:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w, w}
+	case flusher | hijacker | readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	case flusher | pusher | readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	case hijacker | pusher | readerFrom:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{w, w, w, w, w}
+	case flusher | hijacker | pusher | readerFrom:
+		return w
+	default:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+		}{w, w}
+	}
+}